@@ -1,23 +1,27 @@
-module cr
+module github.com/cirocosta/cr
+
+go 1.20
 
 require (
 	github.com/alexflint/go-arg v0.0.0-20170330211029-cef6506c97e5
+	github.com/fatih/color v0.0.0-20170926111411-5df930a27be2
+	github.com/hashicorp/terraform v0.0.0-20171212233002-681b2e75875e
+	github.com/mattn/go-isatty v0.0.0-20170307163044-57fdcb988a5c
+	github.com/pkg/errors v0.8.0
+	github.com/rs/zerolog v1.3.0
+	github.com/stretchr/testify v1.1.4
+	gopkg.in/yaml.v2 v2.0.0-20171116090243-287cf08546ab
+)
+
+require (
 	github.com/alexflint/go-scalar v0.0.0-20170216015739-45e5d6cd8605 // indirect
-	github.com/cirocosta/cr v0.0.0-20171218233017-dc8c1f40647b
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/fatih/color v0.0.0-20170926111411-5df930a27be2
 	github.com/hashicorp/errwrap v0.0.0-20141028054710-7554cd9344ce // indirect
 	github.com/hashicorp/go-multierror v0.0.0-20171204182908-b7773ae21874 // indirect
 	github.com/hashicorp/logutils v1.0.0 // indirect
-	github.com/hashicorp/terraform v0.0.0-20171212233002-681b2e75875e
 	github.com/kr/pretty v0.1.0 // indirect
 	github.com/mattn/go-colorable v0.0.0-20170210172801-5411d3eea597 // indirect
-	github.com/mattn/go-isatty v0.0.0-20170307163044-57fdcb988a5c // indirect
-	github.com/pkg/errors v0.8.0
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/rs/zerolog v1.3.0
-	github.com/stretchr/testify v1.1.4
 	golang.org/x/sys v0.0.0-20170213225739-e24f485414ae // indirect
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
-	gopkg.in/yaml.v2 v2.0.0-20171116090243-287cf08546ab
 )
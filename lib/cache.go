@@ -0,0 +1,226 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CacheEntry records a successful execution of a job
+// so that it can be skipped when nothing changed.
+type CacheEntry struct {
+	Key    string    `json:"key"`
+	Output string    `json:"output"`
+	Time   time.Time `json:"time"`
+}
+
+// Cache stores cache entries as files under a
+// directory, one per job.
+type Cache struct {
+	directory string
+}
+
+// NewCache instantiates a Cache that keeps its entries
+// under `directory`, creating it if needed.
+func NewCache(directory string) (c *Cache, err error) {
+	err = os.MkdirAll(directory, 0755)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to create cache directory %s",
+			directory)
+		return
+	}
+
+	c = &Cache{
+		directory: directory,
+	}
+	return
+}
+
+func (c *Cache) entryPath(id string) string {
+	return filepath.Join(c.directory, url.PathEscape(id)+".json")
+}
+
+// Lookup retrieves the entry stored for the job `id` if
+// it was recorded with the same key.
+func (c *Cache) Lookup(id, key string) (entry *CacheEntry, found bool, err error) {
+	content, err := ioutil.ReadFile(c.entryPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	entry = &CacheEntry{}
+	err = json.Unmarshal(content, entry)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to parse cache entry for job %s", id)
+		return
+	}
+
+	found = entry.Key == key
+	return
+}
+
+// Store records the entry for the job `id`.
+func (c *Cache) Store(id string, entry *CacheEntry) (err error) {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to serialize cache entry for job %s", id)
+		return
+	}
+
+	err = ioutil.WriteFile(c.entryPath(id), content, 0644)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to write cache entry for job %s", id)
+		return
+	}
+
+	return
+}
+
+// ComputeCacheKey hashes everything that can influence the
+// result of a job: its rendered argv, directory and
+// environment, the contents of the files matching its
+// `Inputs` and the keys and outputs of its dependencies.
+// Dependencies that executed a command in this run also
+// contribute the run id, as there's no telling what they
+// changed, so that their dependents don't hit the cache.
+// It must be called after the job fields have been resolved.
+func (e *Executor) ComputeCacheKey(j *Job) (key string, err error) {
+	var (
+		h     = sha256.New()
+		files []string
+	)
+
+	for _, arg := range e.JobArgv(j) {
+		fmt.Fprintf(h, "argv\x00%s\x00", arg)
+	}
+	fmt.Fprintf(h, "directory\x00%s\x00", j.Directory)
+
+	envKeys := make([]string, 0, len(j.Env))
+	for k := range j.Env {
+		envKeys = append(envKeys, k)
+	}
+	sort.Strings(envKeys)
+
+	for _, k := range envKeys {
+		fmt.Fprintf(h, "env\x00%s=%s\x00", k, j.Env[k])
+	}
+
+	for _, pattern := range j.Inputs {
+		var matches []string
+
+		matches, err = expandGlob(j.resolvePath(pattern))
+		if err != nil {
+			err = errors.Wrapf(err,
+				"invalid input pattern %s", pattern)
+			return
+		}
+
+		fmt.Fprintf(h, "pattern\x00%s\x00", pattern)
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		err = hashFile(h, file)
+		if err != nil {
+			return
+		}
+	}
+
+	deps := append([]string{}, j.DependsOn...)
+	sort.Strings(deps)
+
+	for _, dep := range deps {
+		depJob := e.jobsMap[dep]
+		fmt.Fprintf(h, "dep\x00%s\x00%s\x00%s\x00",
+			dep, depJob.CacheKey, depJob.Output)
+		if depJob.executed {
+			fmt.Fprintf(h, "run\x00%s\x00", e.runId)
+		}
+	}
+
+	key = hex.EncodeToString(h.Sum(nil))
+	return
+}
+
+// hashFile writes the path and contents of a file to
+// the hash. Directories only contribute their path.
+func hashFile(h io.Writer, file string) (err error) {
+	fmt.Fprintf(h, "file\x00%s\x00", file)
+
+	f, err := os.Open(file)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to open input file %s", file)
+		return
+	}
+	defer f.Close()
+
+	finfo, err := f.Stat()
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to stat input file %s", file)
+		return
+	}
+
+	if finfo.IsDir() {
+		return
+	}
+
+	_, err = io.Copy(h, f)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to read input file %s", file)
+		return
+	}
+
+	return
+}
+
+// checkCache computes the cache key of the job and looks
+// for a matching entry whose outputs are still present,
+// restoring the captured output in case of a hit.
+func (e *Executor) checkCache(j *Job) (hit bool, err error) {
+	var entry *CacheEntry
+
+	j.CacheKey, err = e.ComputeCacheKey(j)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to compute cache key")
+		return
+	}
+
+	entry, hit, err = e.cache.Lookup(j.Id, j.CacheKey)
+	if err != nil || !hit {
+		return
+	}
+
+	for _, output := range j.Outputs {
+		_, err = os.Stat(j.resolvePath(output))
+		if err != nil {
+			hit = false
+			err = nil
+			return
+		}
+	}
+
+	j.Output = entry.Output
+	return
+}
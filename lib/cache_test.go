@@ -0,0 +1,98 @@
+package lib
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteCache(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		input  = filepath.Join(dir, "input.txt")
+		output = filepath.Join(dir, "output.txt")
+	)
+
+	require.NoError(t, ioutil.WriteFile(input, []byte("v1"), 0644))
+
+	run := func() (job *Job, downstream *Job) {
+		job = &Job{
+			Id:            "build",
+			Run:           "cp input.txt output.txt && echo built",
+			Directory:     dir,
+			CaptureOutput: true,
+			Inputs:        []string{"input*"},
+			Outputs:       []string{"output.txt"},
+		}
+		downstream = &Job{
+			Id:        "print",
+			Run:       "echo {{ .Jobs.build.Output }}",
+			DependsOn: []string{"build"},
+		}
+
+		e, err := New(&Config{
+			Runtime: Runtime{LogsDirectory: dir},
+			Jobs:    []*Job{job, downstream},
+		})
+		require.NoError(t, err)
+		require.NoError(t, e.Execute(context.Background()))
+
+		return
+	}
+
+	job, _ := run()
+	assert.Equal(t, ActivitySuccess, job.Status)
+
+	job, downstream := run()
+	assert.Equal(t, ActivityCached, job.Status)
+	assert.Equal(t, "built", job.Output)
+	assert.Equal(t, "echo built", downstream.Run)
+
+	require.NoError(t, ioutil.WriteFile(input, []byte("v2"), 0644))
+	job, _ = run()
+	assert.Equal(t, ActivitySuccess, job.Status)
+
+	job, _ = run()
+	assert.Equal(t, ActivityCached, job.Status)
+
+	require.NoError(t, os.Remove(output))
+	job, _ = run()
+	assert.Equal(t, ActivitySuccess, job.Status)
+}
+
+func TestComputeCacheKeyDependencies(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		upstream = &Job{Id: "upstream", Output: "out"}
+		job      = &Job{Id: "job", DependsOn: []string{"upstream"}}
+	)
+
+	e, err := New(&Config{
+		Runtime: Runtime{LogsDirectory: dir},
+		Jobs:    []*Job{upstream, job},
+	})
+	require.NoError(t, err)
+
+	cached, err := e.ComputeCacheKey(job)
+	require.NoError(t, err)
+
+	again, err := e.ComputeCacheKey(job)
+	require.NoError(t, err)
+	assert.Equal(t, cached, again)
+
+	upstream.executed = true
+	e.runId = "run-1"
+	executed, err := e.ComputeCacheKey(job)
+	require.NoError(t, err)
+	assert.NotEqual(t, cached, executed)
+
+	e.runId = "run-2"
+	nextRun, err := e.ComputeCacheKey(job)
+	require.NoError(t, err)
+	assert.NotEqual(t, executed, nextRun)
+}
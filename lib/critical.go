@@ -0,0 +1,125 @@
+package lib
+
+import (
+	"time"
+)
+
+// CriticalPath is the chain of dependent jobs that took the
+// longest to execute, i.e., the one that bounds the wall-clock
+// duration of an execution with unlimited parallelism.
+type CriticalPath struct {
+	// Jobs lists the ids of the jobs on the path in the
+	// order they executed.
+	Jobs []string
+
+	// Duration is the sum of the durations of the jobs
+	// on the path.
+	Duration time.Duration
+
+	// Durations maps the id of each job that ran in this run
+	// to the time it took to execute.
+	Durations map[string]time.Duration
+
+	// Slack maps the id of each job that ran in this run to
+	// how much longer it could have taken without making the
+	// critical path any longer. Jobs on the critical path have
+	// none.
+	Slack map[string]time.Duration
+}
+
+// jobDuration retrieves how long a job took to execute in
+// the current run, indicating whether it ran to completion.
+// Jobs that got aborted don't count as having run as they
+// were cut short.
+func (e *Executor) jobDuration(j *Job) (duration time.Duration, ran bool) {
+	if e.restored[j.Id] || j.StartTime == nil || j.EndTime == nil ||
+		j.Status == ActivityAborted {
+		return
+	}
+
+	duration = j.EndTime.Sub(*j.StartTime)
+	ran = true
+	return
+}
+
+// GetCriticalPath computes the critical path of the execution
+// graph using the measured duration of each job, as well as
+// the slack of every job. Jobs that didn't run count as taking
+// no time but are left out of the path and the slack.
+func (e *Executor) GetCriticalPath() (cp *CriticalPath) {
+	var (
+		jobs          = e.SortedJobs()
+		earliestStart = map[string]time.Duration{}
+		earliestEnd   = map[string]time.Duration{}
+		latestEnd     = map[string]time.Duration{}
+		durations     = map[string]time.Duration{}
+		ran           = map[string]bool{}
+		dependents    = map[string][]string{}
+		last          *Job
+	)
+
+	cp = &CriticalPath{
+		Jobs:      []string{},
+		Durations: map[string]time.Duration{},
+		Slack:     map[string]time.Duration{},
+	}
+
+	for _, job := range jobs {
+		durations[job.Id], ran[job.Id] = e.jobDuration(job)
+		if ran[job.Id] {
+			cp.Durations[job.Id] = durations[job.Id]
+		}
+
+		for _, dep := range job.DependsOn {
+			if earliestEnd[dep] > earliestStart[job.Id] {
+				earliestStart[job.Id] = earliestEnd[dep]
+			}
+			dependents[dep] = append(dependents[dep], job.Id)
+		}
+
+		earliestEnd[job.Id] = earliestStart[job.Id] + durations[job.Id]
+		if ran[job.Id] && (last == nil || earliestEnd[job.Id] > earliestEnd[last.Id]) {
+			last = job
+		}
+	}
+
+	if last == nil {
+		return
+	}
+
+	cp.Duration = earliestEnd[last.Id]
+
+	for idx := len(jobs) - 1; idx >= 0; idx-- {
+		job := jobs[idx]
+
+		latestEnd[job.Id] = cp.Duration
+		for _, dependent := range dependents[job.Id] {
+			latestStart := latestEnd[dependent] - durations[dependent]
+			if latestStart < latestEnd[job.Id] {
+				latestEnd[job.Id] = latestStart
+			}
+		}
+
+		if ran[job.Id] {
+			cp.Slack[job.Id] = latestEnd[job.Id] - earliestEnd[job.Id]
+		}
+	}
+
+	for job := last; job != nil; {
+		if ran[job.Id] {
+			cp.Jobs = append([]string{job.Id}, cp.Jobs...)
+		}
+
+		var next *Job
+		for _, dep := range job.DependsOn {
+			if earliestEnd[dep] == earliestStart[job.Id] &&
+				(next == nil || e.jobsIndex[dep] < e.jobsIndex[next.Id]) {
+				next = e.jobsMap[dep]
+			}
+		}
+
+		job = next
+	}
+
+	return
+}
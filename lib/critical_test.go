@@ -0,0 +1,162 @@
+package lib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCriticalPath(t *testing.T) {
+	var testCases = []struct {
+		desc      string
+		jobs      []*Job
+		durations map[string]time.Duration
+		path      []string
+		duration  time.Duration
+		slack     map[string]time.Duration
+	}{
+		{
+			desc:     "no jobs",
+			jobs:     []*Job{},
+			path:     []string{},
+			duration: 0,
+			slack:    map[string]time.Duration{},
+		},
+		{
+			desc: "independent jobs",
+			jobs: []*Job{
+				{Id: "a"},
+				{Id: "b"},
+			},
+			durations: map[string]time.Duration{
+				"a": 1 * time.Second,
+				"b": 3 * time.Second,
+			},
+			path:     []string{"b"},
+			duration: 3 * time.Second,
+			slack: map[string]time.Duration{
+				"a": 2 * time.Second,
+				"b": 0,
+			},
+		},
+		{
+			desc: "longest chain wins over the longest job",
+			jobs: []*Job{
+				{Id: "a"},
+				{Id: "b"},
+				{Id: "c", DependsOn: []string{"b"}},
+				{Id: "d", DependsOn: []string{"a", "c"}},
+			},
+			durations: map[string]time.Duration{
+				"a": 3 * time.Second,
+				"b": 1 * time.Second,
+				"c": 4 * time.Second,
+				"d": 1 * time.Second,
+			},
+			path:     []string{"b", "c", "d"},
+			duration: 6 * time.Second,
+			slack: map[string]time.Duration{
+				"a": 2 * time.Second,
+				"b": 0,
+				"c": 0,
+				"d": 0,
+			},
+		},
+		{
+			desc: "jobs that didn't run are left out",
+			jobs: []*Job{
+				{Id: "a"},
+				{Id: "b", DependsOn: []string{"a"}},
+				{Id: "c"},
+			},
+			durations: map[string]time.Duration{
+				"a": 2 * time.Second,
+			},
+			path:     []string{"a"},
+			duration: 2 * time.Second,
+			slack: map[string]time.Duration{
+				"a": 0,
+			},
+		},
+		{
+			desc: "path through jobs that didn't run",
+			jobs: []*Job{
+				{Id: "a"},
+				{Id: "b", DependsOn: []string{"a"}, Status: ActivityCached},
+				{Id: "c", DependsOn: []string{"b"}},
+				{Id: "d"},
+			},
+			durations: map[string]time.Duration{
+				"a": 1 * time.Second,
+				"c": 2 * time.Second,
+				"d": 1 * time.Second,
+			},
+			path:     []string{"a", "c"},
+			duration: 3 * time.Second,
+			slack: map[string]time.Duration{
+				"a": 0,
+				"c": 0,
+				"d": 2 * time.Second,
+			},
+		},
+		{
+			desc: "aborted jobs aren't part of the path",
+			jobs: []*Job{
+				{Id: "a", Status: ActivityErrored},
+				{Id: "b", Status: ActivityAborted},
+				{Id: "c", DependsOn: []string{"a"}},
+			},
+			durations: map[string]time.Duration{
+				"a": 1 * time.Second,
+				"b": 3 * time.Second,
+			},
+			path:     []string{"a"},
+			duration: 1 * time.Second,
+			slack: map[string]time.Duration{
+				"a": 0,
+			},
+		},
+		{
+			desc: "nothing ran",
+			jobs: []*Job{
+				{Id: "a", Status: ActivityAborted},
+				{Id: "b", DependsOn: []string{"a"}},
+			},
+			durations: map[string]time.Duration{
+				"a": 1 * time.Second,
+			},
+			path:     []string{},
+			duration: 0,
+			slack:    map[string]time.Duration{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			start := time.Now()
+
+			for _, job := range tc.jobs {
+				duration, ok := tc.durations[job.Id]
+				if !ok {
+					continue
+				}
+
+				end := start.Add(duration)
+				job.StartTime, job.EndTime = &start, &end
+			}
+
+			e, err := New(&Config{
+				Runtime: Runtime{LogsDirectory: t.TempDir()},
+				Jobs:    tc.jobs,
+			})
+			require.NoError(t, err)
+
+			cp := e.GetCriticalPath()
+			assert.Equal(t, tc.path, cp.Jobs)
+			assert.Equal(t, tc.duration, cp.Duration)
+			assert.Equal(t, tc.slack, cp.Slack)
+		})
+	}
+}
@@ -0,0 +1,394 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// dashboardInterval is how often the dashboard
+	// gets redrawn.
+	dashboardInterval = 100 * time.Millisecond
+
+	// dashboardLogWidth is the maximum number of characters
+	// of the last line of the logs shown for each job.
+	dashboardLogWidth = 60
+
+	// dashboardLogTailBytes is how much of the end of the
+	// logs of a job is read to find its last line.
+	dashboardLogTailBytes = 4096
+)
+
+var (
+	spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+)
+
+// Dashboard is an interactive alternative to Ui meant for
+// terminals: it keeps redrawing a table with the state,
+// elapsed time and last line of the logs of every job that
+// hasn't finished yet. Finished jobs get collapsed into a
+// single line printed above the table.
+type Dashboard struct {
+	writer io.Writer
+	jobs   []*dashboardJob
+	idSize int
+
+	// lines is the number of lines drawn by the last
+	// frame that need to be erased by the next one.
+	lines int
+	frame int
+
+	// height is the number of lines of the terminal, which
+	// frames must not exceed as lines that scroll out of the
+	// screen can't be erased. Zero means no limit.
+	height int
+
+	done    chan struct{}
+	stopped chan struct{}
+	sync.Mutex
+}
+
+// dashboardJob is what the dashboard knows about a job,
+// kept separately from the job itself as it gets updated
+// by the executor concurrently.
+type dashboardJob struct {
+	id          string
+	status      ActivityType
+	logFilepath string
+	attempt     int
+	attempts    int
+	startTime   time.Time
+	endTime     time.Time
+	collapsed   bool
+}
+
+// NewDashboard instantiates a Dashboard that displays
+// `jobs` in the order given.
+func NewDashboard(w io.Writer, jobs []*Job) (d *Dashboard) {
+	d = &Dashboard{
+		writer: w,
+		height: terminalHeight(w),
+	}
+
+	for _, job := range jobs {
+		if len(job.Id) > d.idSize {
+			d.idSize = len(job.Id)
+		}
+
+		d.jobs = append(d.jobs, &dashboardJob{
+			id:       job.Id,
+			attempt:  1,
+			attempts: job.Retries + 1,
+		})
+	}
+
+	return
+}
+
+// WriteActivity records a job status transition to be
+// displayed in the next frame.
+func (d *Dashboard) WriteActivity(a *Activity) (err error) {
+	d.Lock()
+	defer d.Unlock()
+
+	var job *dashboardJob
+	for _, candidate := range d.jobs {
+		if candidate.id == a.Job.Id {
+			job = candidate
+			break
+		}
+	}
+
+	if job == nil {
+		err = errors.Errorf(
+			"unknown job %s", a.Job.Id)
+		return
+	}
+
+	job.status = a.Type
+
+	switch a.Type {
+	case ActivityStarted:
+		job.startTime = a.Time
+		job.logFilepath = a.Job.LogFilepath
+	case ActivityRetrying:
+		job.attempt = a.Attempt + 1
+	case ActivityErrored, ActivitySuccess, ActivityAborted, ActivitySkipped,
+		ActivityTimeout, ActivityCached, ActivityUpToDate, ActivityRestored:
+		job.endTime = a.Time
+	case ActivityQueued:
+	default:
+		err = errors.Errorf(
+			"unknown activity type %+v", a)
+		return
+	}
+
+	return
+}
+
+// Start redraws the dashboard periodically until Stop
+// is called.
+func (d *Dashboard) Start() {
+	d.done = make(chan struct{})
+	d.stopped = make(chan struct{})
+
+	go func() {
+		defer close(d.stopped)
+
+		ticker := time.NewTicker(dashboardInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-d.done:
+				return
+			case <-ticker.C:
+				d.Redraw(time.Now())
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic redraws and draws a last frame.
+func (d *Dashboard) Stop() {
+	if d.done != nil {
+		close(d.done)
+		<-d.stopped
+	}
+
+	d.Redraw(time.Now())
+}
+
+// Redraw erases the previous frame and draws a new one
+// considering `now` as the current time.
+func (d *Dashboard) Redraw(now time.Time) {
+	d.Lock()
+	defer d.Unlock()
+
+	var (
+		buf                       bytes.Buffer
+		finished, running, queued int
+		pending                   []dashboardRow
+	)
+
+	if height := terminalHeight(d.writer); height > 0 {
+		d.height = height
+	}
+
+	if d.lines > 0 {
+		fmt.Fprintf(&buf, "\x1b[%dA\x1b[J", d.lines)
+	}
+
+	spinner := spinnerFrames[d.frame%len(spinnerFrames)]
+	d.frame++
+
+	for _, job := range d.jobs {
+		if !job.endTime.IsZero() {
+			finished++
+
+			if !job.collapsed {
+				job.collapsed = true
+				fmt.Fprintln(&buf, WriterMapping[job.status].Sprint(d.finishedLine(job)))
+			}
+			continue
+		}
+
+		switch job.status {
+		case ActivityStarted, ActivityRetrying:
+			running++
+			pending = append(pending, dashboardRow{
+				line: d.runningLine(job, spinner, now),
+			})
+		case ActivityQueued:
+			queued++
+			pending = append(pending, dashboardRow{
+				line: WriterMapping[job.status].Sprintf(
+					"  %-*s  %s", d.idSize, job.id, ActivityMapping[job.status]),
+				rank: 1,
+			})
+		default:
+			pending = append(pending, dashboardRow{
+				line: fmt.Sprintf(
+					"  %-*s  %s", d.idSize, job.id, "WAITING"),
+				rank: 2,
+			})
+		}
+	}
+
+	rows := d.fitRows(pending)
+	rows = append(rows, fmt.Sprintf(
+		"%d/%d finished, %d running, %d queued",
+		finished, len(d.jobs), running, queued))
+
+	// rows wider than the terminal must not wrap, or the
+	// next redraw would erase fewer lines than were drawn
+	buf.WriteString("\x1b[?7l")
+	for _, row := range rows {
+		fmt.Fprintln(&buf, row)
+	}
+	buf.WriteString("\x1b[?7h")
+	d.lines = len(rows)
+
+	d.writer.Write(buf.Bytes())
+}
+
+// dashboardRow is the line of a job that hasn't finished,
+// ranked by how relevant it is: running jobs first, then
+// queued and waiting ones.
+type dashboardRow struct {
+	line string
+	rank int
+}
+
+// fitRows picks the rows to draw so that, along with the
+// summary line and the line the cursor is left at, the
+// frame fits in the terminal. The least relevant rows get
+// replaced by a line telling how many were left out.
+func (d *Dashboard) fitRows(pending []dashboardRow) (rows []string) {
+	limit := d.height - 2
+	if d.height <= 0 || len(pending) <= limit {
+		for _, row := range pending {
+			rows = append(rows, row.line)
+		}
+		return
+	}
+
+	if limit <= 0 {
+		return
+	}
+
+	var (
+		keep   = limit - 1
+		ranked = make([]int, len(pending))
+		shown  = map[int]bool{}
+	)
+
+	for idx := range ranked {
+		ranked[idx] = idx
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return pending[ranked[i]].rank < pending[ranked[j]].rank
+	})
+
+	for _, idx := range ranked[:keep] {
+		shown[idx] = true
+	}
+
+	for idx, row := range pending {
+		if shown[idx] {
+			rows = append(rows, row.line)
+		}
+	}
+
+	rows = append(rows, fmt.Sprintf(
+		"  ... %d more", len(pending)-keep))
+	return
+}
+
+// terminalHeight retrieves the number of lines of the
+// terminal `w` writes to, or zero if it's not a terminal.
+func terminalHeight(w io.Writer) int {
+	var ws struct {
+		Row, Col, Xpixel, Ypixel uint16
+	}
+
+	file, ok := w.(*os.File)
+	if !ok {
+		return 0
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, file.Fd(),
+		uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0
+	}
+
+	return int(ws.Row)
+}
+
+func (d *Dashboard) finishedLine(job *dashboardJob) string {
+	if job.startTime.IsZero() {
+		return fmt.Sprintf("  %-*s  %s",
+			d.idSize, job.id, ActivityMapping[job.status])
+	}
+
+	return fmt.Sprintf("  %-*s  %-10s  %s",
+		d.idSize, job.id,
+		ActivityMapping[job.status],
+		job.endTime.Sub(job.startTime).Round(time.Millisecond))
+}
+
+func (d *Dashboard) runningLine(job *dashboardJob, spinner string, now time.Time) string {
+	status := ActivityMapping[ActivityStarted]
+	if job.attempts > 1 {
+		status = fmt.Sprintf("%s %d/%d", status, job.attempt, job.attempts)
+	}
+
+	return fmt.Sprintf("%s %-*s  %s  %8s  %s",
+		WriterMapping[ActivityStarted].Sprint(spinner),
+		d.idSize, job.id,
+		WriterMapping[ActivityStarted].Sprintf("%-10s", status),
+		now.Sub(job.startTime).Round(100*time.Millisecond),
+		lastLogLine(job.logFilepath, dashboardLogWidth))
+}
+
+// lastLogLine retrieves the last non-empty line written to
+// a log file, truncated to `width` characters. Errors are
+// ignored as it's only used for informative purposes.
+func lastLogLine(file string, width int) (line string) {
+	if file == "" {
+		return
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	finfo, err := f.Stat()
+	if err != nil {
+		return
+	}
+
+	offset := finfo.Size() - dashboardLogTailBytes
+	if offset < 0 {
+		offset = 0
+	}
+
+	content := make([]byte, finfo.Size()-offset)
+	_, err = f.ReadAt(content, offset)
+	if err != nil && err != io.EOF {
+		return
+	}
+
+	lines := strings.FieldsFunc(string(content), func(r rune) bool {
+		return r == '\n' || r == '\r'
+	})
+
+	for idx := len(lines) - 1; idx >= 0 && line == ""; idx-- {
+		line = strings.TrimSpace(strings.Map(func(r rune) rune {
+			if r < ' ' || r == 0x7f {
+				return ' '
+			}
+			return r
+		}, lines[idx]))
+	}
+
+	if utf8.RuneCountInString(line) > width {
+		line = string([]rune(line)[:width-3]) + "..."
+	}
+
+	return
+}
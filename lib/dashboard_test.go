@@ -0,0 +1,159 @@
+package lib
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardRedraw(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	var (
+		buf     bytes.Buffer
+		now     = time.Now()
+		logFile = filepath.Join(t.TempDir(), "build")
+		jobs    = []*Job{
+			{Id: "build", LogFilepath: logFile},
+			{Id: "lint"},
+			{Id: "test"},
+		}
+	)
+
+	require.NoError(t, ioutil.WriteFile(logFile,
+		[]byte("compiling\nlinking\n\n"), 0644))
+
+	d := NewDashboard(&buf, jobs)
+
+	require.NoError(t, d.WriteActivity(&Activity{
+		Type: ActivityStarted, Job: jobs[0], Time: now,
+	}))
+	require.NoError(t, d.WriteActivity(&Activity{
+		Type: ActivityQueued, Job: jobs[1], Time: now,
+	}))
+
+	d.Redraw(now.Add(2 * time.Second))
+	first := buf.String()
+	buf.Reset()
+
+	assert.Contains(t, first, "build  STARTED")
+	assert.Contains(t, first, "2s  linking")
+	assert.Contains(t, first, "lint   QUEUED")
+	assert.Contains(t, first, "test   WAITING")
+	assert.Contains(t, first, "0/3 finished, 1 running, 1 queued")
+	assert.NotContains(t, first, "\x1b[J")
+	assert.True(t, strings.HasPrefix(first, "\x1b[?7l"))
+	assert.True(t, strings.HasSuffix(first, "\x1b[?7h"))
+
+	require.NoError(t, d.WriteActivity(&Activity{
+		Type: ActivitySuccess, Job: jobs[0], Time: now.Add(3 * time.Second),
+	}))
+
+	d.Redraw(now.Add(4 * time.Second))
+	second := buf.String()
+	buf.Reset()
+
+	assert.True(t, strings.HasPrefix(second, "\x1b[4A\x1b[J"))
+	assert.Contains(t, second, "build  SUCCESS     3s\n\x1b[?7l")
+	assert.Contains(t, second, "1/3 finished, 0 running, 1 queued")
+
+	d.Redraw(now.Add(5 * time.Second))
+	third := buf.String()
+
+	assert.True(t, strings.HasPrefix(third, "\x1b[3A\x1b[J"))
+	assert.NotContains(t, third, "build")
+}
+
+func TestDashboardRedrawFitsTerminal(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	var (
+		buf  bytes.Buffer
+		now  = time.Now()
+		jobs []*Job
+	)
+
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		jobs = append(jobs, &Job{Id: id})
+	}
+
+	d := NewDashboard(&buf, jobs)
+	d.height = 5
+
+	require.NoError(t, d.WriteActivity(&Activity{
+		Type: ActivityQueued, Job: jobs[3], Time: now,
+	}))
+	require.NoError(t, d.WriteActivity(&Activity{
+		Type: ActivityStarted, Job: jobs[5], Time: now,
+	}))
+
+	d.Redraw(now)
+	frame := buf.String()
+	buf.Reset()
+
+	assert.Equal(t, 4, d.lines)
+	assert.Contains(t, frame, "d  QUEUED")
+	assert.Contains(t, frame, "f  STARTED")
+	assert.Contains(t, frame, "  ... 4 more\n")
+	assert.NotContains(t, frame, "WAITING")
+	assert.Contains(t, frame, "0/6 finished, 1 running, 1 queued")
+
+	d.Redraw(now)
+	assert.True(t, strings.HasPrefix(buf.String(), "\x1b[4A\x1b[J"))
+}
+
+func TestLastLogLine(t *testing.T) {
+	var testCases = []struct {
+		desc     string
+		content  string
+		width    int
+		expected string
+	}{
+		{
+			desc:     "empty",
+			content:  "",
+			width:    10,
+			expected: "",
+		},
+		{
+			desc:     "skips trailing empty lines",
+			content:  "first\nsecond\n\n  \n",
+			width:    10,
+			expected: "second",
+		},
+		{
+			desc:     "considers carriage returns",
+			content:  "progress 10%\rprogress 20%",
+			width:    20,
+			expected: "progress 20%",
+		},
+		{
+			desc:     "truncates long lines",
+			content:  "a very long line",
+			width:    10,
+			expected: "a very ...",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "log")
+			require.NoError(t, ioutil.WriteFile(file, []byte(tc.content), 0644))
+
+			assert.Equal(t, tc.expected, lastLogLine(file, tc.width))
+		})
+	}
+
+	assert.Equal(t, "", lastLogLine("/does/not/exist", 10))
+}
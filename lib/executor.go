@@ -3,10 +3,13 @@ package lib
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/terraform/dag"
@@ -14,6 +17,12 @@ import (
 	"github.com/rs/zerolog"
 )
 
+var (
+	// DefaultShell is the shell that executes the Run
+	// command of the jobs unless configured otherwise.
+	DefaultShell = []string{"/bin/bash", "-c"}
+)
+
 // Executor encapsulates the execution
 // context of a graph of jobs.
 type Executor struct {
@@ -21,7 +30,34 @@ type Executor struct {
 	graph         *dag.AcyclicGraph
 	logger        zerolog.Logger
 	jobsMap       map[string]*Job
+	jobsIndex     map[string]int
+	excluded      []string
+	reincluded    []string
+	restored      map[string]bool
+	scheduler     *Scheduler
+	runners       map[string]Runner
+	cache         *Cache
 	logsDirectory string
+	configFile    string
+	runId         string
+	startTime     time.Time
+	endTime       time.Time
+
+	// stateLock serializes the updates to the state
+	// file, which keeps `jobStates` of the jobs that
+	// finished so far.
+	stateLock *sync.Mutex
+	jobStates map[string]*JobState
+
+	// outputLock serializes the lines written by jobs
+	// to stdout and stderr, which are prefixed to be
+	// `prefixWidth` wide.
+	outputLock  sync.Locker
+	prefixWidth int
+
+	// output is where the output of the jobs gets
+	// written to, either streamed or grouped.
+	output io.Writer
 }
 
 // New instantiates a new Executor from
@@ -34,18 +70,101 @@ func New(cfg *Config) (e Executor, err error) {
 		return
 	}
 
-	graph, err := BuildDependencyGraph(cfg.Jobs)
+	cfg.Jobs, err = ExpandMatrices(cfg.Jobs)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to expand job matrices")
+		return
+	}
+
+	// ExcludeTags only narrows down the selection by Tags or,
+	// without it, the whole plan when no targets are given:
+	// explicit targets are never widened nor filtered.
+	explicit := ResolveJobRefs(cfg.Jobs, cfg.Runtime.Targets)
+	targets := explicit
+	if cfg.Runtime.Tags != "" ||
+		(cfg.Runtime.ExcludeTags != "" && len(explicit) == 0) {
+		var selected []string
+
+		selected, err = SelectJobsByTags(cfg.Jobs,
+			cfg.Runtime.Tags, cfg.Runtime.ExcludeTags)
+		if err != nil {
+			err = errors.Wrapf(err,
+				"failed to select jobs by tags")
+			return
+		}
+
+		if len(selected) == 0 {
+			err = errors.Errorf("no jobs match the tags selection")
+			return
+		}
+
+		targets = append(selected, explicit...)
+	}
+
+	graph, err := BuildDependencyGraph(cfg.Jobs, targets)
 	if err != nil {
 		err = errors.Wrapf(err,
 			"failed to create dependency graph")
 		return
 	}
 
+	e.excluded, err = ExcludeJobs(&graph, cfg.Jobs,
+		ResolveJobRefs(cfg.Jobs, cfg.Runtime.Skip))
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to exclude jobs from dependency graph")
+		return
+	}
+
+	if cfg.Runtime.ExcludeTags != "" {
+		var deselected []string
+
+		deselected, err = SelectJobsByTags(cfg.Jobs, cfg.Runtime.ExcludeTags, "")
+		if err != nil {
+			err = errors.Wrapf(err,
+				"failed to select jobs by tags")
+			return
+		}
+
+		isTarget := map[string]bool{}
+		for _, id := range explicit {
+			isTarget[id] = true
+		}
+
+		for _, job := range cfg.Jobs {
+			for _, id := range deselected {
+				if job.Id == id && graph.HasVertex(job) && !isTarget[id] {
+					e.reincluded = append(e.reincluded, id)
+				}
+			}
+		}
+	}
+
 	if cfg.Runtime.LogsDirectory == "" {
 		err = errors.Errorf("LogsDirectory must be specified")
 		return
 	}
 
+	switch cfg.Runtime.Output {
+	case "", OutputStream:
+	case OutputGrouped:
+		if cfg.Runtime.Stdout {
+			err = errors.Errorf("Stdout can't be used with grouped output")
+			return
+		}
+	default:
+		err = errors.Errorf(
+			"unknown output mode %s (expected %s or %s)",
+			cfg.Runtime.Output, OutputStream, OutputGrouped)
+		return
+	}
+
+	if cfg.Runtime.FailFast && cfg.Runtime.KeepGoing {
+		err = errors.Errorf("FailFast and KeepGoing can't be used together")
+		return
+	}
+
 	finfo, err = os.Stat(cfg.Runtime.LogsDirectory)
 	if err != nil {
 		err = errors.Wrapf(err,
@@ -61,22 +180,128 @@ func New(cfg *Config) (e Executor, err error) {
 		return
 	}
 
+	e.scheduler, err = NewScheduler(cfg.Runtime.MaxParallel, cfg.Resources)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to create scheduler")
+		return
+	}
+
+	e.runners = map[string]Runner{
+		LocalRunnerName: &LocalRunner{},
+	}
+	for name, runner := range cfg.Runners {
+		e.runners[name] = runner
+	}
+
+	for _, job := range cfg.Jobs {
+		_, present := e.runners[job.runnerName()]
+		if !present {
+			err = errors.Errorf(
+				"runner %s of job %s does not exist",
+				job.runnerName(), job.Id)
+			return
+		}
+
+		if job.Run != "" && len(job.Command) > 0 {
+			err = errors.Errorf(
+				"job %s can't have both Run and Command", job.Id)
+			return
+		}
+
+		if job.Func != nil && (job.Run != "" || job.Runner != "") {
+			err = errors.Errorf(
+				"job %s can't have Func along with Run or Runner", job.Id)
+			return
+		}
+
+		err = e.scheduler.Validate(job.Uses)
+		if err != nil {
+			err = errors.Wrapf(err,
+				"invalid resources for job %s", job.Id)
+			return
+		}
+	}
+
+	if cfg.Runtime.File != "" {
+		e.configFile, err = filepath.Abs(cfg.Runtime.File)
+		if err != nil {
+			err = errors.Wrapf(err,
+				"failed to resolve path of config file %s",
+				cfg.Runtime.File)
+			return
+		}
+	}
+
 	e.logsDirectory = cfg.Runtime.LogsDirectory
+	e.runId = NewRunId(time.Now())
 	e.config = cfg
 	e.graph = &graph
 	e.jobsMap = map[string]*Job{}
+	e.jobsIndex = map[string]int{}
+	e.restored = map[string]bool{}
+	e.outputLock = cfg.OutputLock
+	if e.outputLock == nil {
+		e.outputLock = &sync.Mutex{}
+	}
+	e.output = os.Stdout
+	// keep stdout clean for the JSON report when it goes there
+	if cfg.Runtime.ReportJson == "-" {
+		e.output = os.Stderr
+	}
+	e.stateLock = &sync.Mutex{}
+	e.jobStates = map[string]*JobState{}
 	e.logger = zerolog.New(os.Stdout).
 		With().
 		Str("from", "executor").
 		Logger()
 
-	for _, job := range cfg.Jobs {
+	for idx, job := range cfg.Jobs {
 		e.jobsMap[job.Id] = job
+		e.jobsIndex[job.Id] = idx
+
+		if graph.HasVertex(job) && len(job.Id) > e.prefixWidth {
+			e.prefixWidth = len(job.Id)
+		}
+
+		if len(job.Inputs) > 0 && e.cache == nil {
+			e.cache, err = NewCache(path.Join(
+				cfg.Runtime.LogsDirectory, ".cache"))
+			if err != nil {
+				return
+			}
+		}
 	}
 
 	return
 }
 
+// ExcludedJobs lists the jobs that won't be executed
+// because they depend on jobs that were skipped.
+func (e *Executor) ExcludedJobs() (res []string) {
+	res = e.excluded
+	return
+}
+
+// ReincludedJobs lists the jobs deselected by ExcludeTags
+// that will be executed anyway because jobs that are part
+// of the execution depend on them.
+func (e *Executor) ReincludedJobs() (res []string) {
+	res = e.reincluded
+	return
+}
+
+// RunId retrieves the identifier of the execution.
+func (e *Executor) RunId() string {
+	return e.runId
+}
+
+// RunDirectory retrieves the directory where the logs
+// and state of the execution are stored.
+func (e *Executor) RunDirectory() string {
+	return path.Join(e.logsDirectory, e.runId)
+}
+
 // GetDotGraph retrieves a `dot` visualization of
 // the dependency graph.
 func (e *Executor) GetDotGraph() (res string) {
@@ -87,9 +312,46 @@ func (e *Executor) GetDotGraph() (res string) {
 // Execute initiates the parallel execution of the
 // jobs.
 func (e *Executor) Execute(ctx context.Context) (err error) {
+	e.startTime = time.Now()
+
+	if e.config.Runtime.Resume {
+		err = e.restoreState()
+		if err != nil {
+			err = errors.Wrapf(err, "failed to resume previous run")
+			return
+		}
+	}
+
+	err = os.MkdirAll(e.RunDirectory(), 0755)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to create run directory %s",
+			e.RunDirectory())
+		return
+	}
+
 	err = e.TraverseAndExecute(ctx, e.graph)
+	e.endTime = time.Now()
+
+	if e.config.Runtime.Output == OutputGrouped {
+		e.writeFailures()
+	}
+
+	saveErr := e.SaveState()
+	if saveErr != nil && err == nil {
+		err = saveErr
+		return
+	}
+
 	if err != nil {
-		err = errors.Wrapf(err, "jobs execution failed")
+		failed := e.FailedJobs()
+		if !e.config.Runtime.KeepGoing || len(failed) == 0 {
+			err = errors.Wrapf(err, "jobs execution failed")
+			return
+		}
+
+		err = errors.Wrapf(err, "jobs execution failed (%s)",
+			strings.Join(failed, ", "))
 		return
 	}
 
@@ -126,7 +388,7 @@ func (e *Executor) ResolveJobLogFilepath(j *Job, renderState *RenderState) (res
 	switch j.LogFilepath {
 	case "":
 		res = path.Join(
-			e.config.Runtime.LogsDirectory,
+			e.RunDirectory(),
 			j.Id)
 	default:
 		res, err = TemplateField(j.LogFilepath, renderState)
@@ -161,6 +423,53 @@ func (e *Executor) ResolveJobRun(j *Job, renderState *RenderState) (res string,
 	return
 }
 
+// ResolveJobCommand templates each element of the
+// Command of a job.
+func (e *Executor) ResolveJobCommand(j *Job, renderState *RenderState) (res []string, err error) {
+	if j == nil || renderState == nil {
+		err = errors.Errorf("job and renderState must be non-nil")
+		return
+	}
+
+	for idx, arg := range j.Command {
+		var rendered string
+
+		rendered, err = TemplateField(arg, renderState)
+		if err != nil {
+			err = errors.Wrapf(err,
+				"couldn't render command argument %d", idx)
+			return
+		}
+
+		res = append(res, rendered)
+	}
+
+	return
+}
+
+// JobArgv retrieves the argv that executes a job: its
+// Command if set (which are the arguments of its Func
+// if it has one) or otherwise its Run command in the
+// context of the shell of the job, of the Runtime or the
+// default one, in that order.
+func (e *Executor) JobArgv(j *Job) (argv []string) {
+	if len(j.Command) > 0 || j.Func != nil {
+		return j.Command
+	}
+
+	shell := DefaultShell
+	switch {
+	case len(j.Shell) > 0:
+		shell = j.Shell
+	case len(e.config.Runtime.Shell) > 0:
+		shell = e.config.Runtime.Shell
+	}
+
+	argv = append(argv, shell...)
+	argv = append(argv, j.Run)
+	return
+}
+
 func (e *Executor) ResolveJobEnv(j *Job, renderState *RenderState) (res map[string]string, err error) {
 	res = map[string]string{}
 
@@ -204,11 +513,16 @@ func (e *Executor) RunJob(ctx context.Context, j *Job) (err error) {
 		execution *Execution
 		logFile   *os.File
 		output    bytes.Buffer
+		attempt   int
+		timedOut  bool
+		skip      bool
+		prefixed  []*PrefixWriter
 
 		stdout      = []io.Writer{}
 		stderr      = []io.Writer{}
 		renderState = &RenderState{
-			Jobs: e.jobsMap,
+			Jobs:   e.jobsMap,
+			Matrix: j.MatrixValues,
 		}
 	)
 
@@ -216,9 +530,14 @@ func (e *Executor) RunJob(ctx context.Context, j *Job) (err error) {
 		stdout = append(stdout, &output)
 	}
 
-	if e.config.Runtime.Stdout {
-		stdout = append(stdout, os.Stdout)
-		stderr = append(stdout, os.Stderr)
+	if e.config.Runtime.Stdout || e.config.Runtime.Output == OutputStream {
+		prefixed = []*PrefixWriter{
+			e.newPrefixWriter(e.output, j),
+			e.newPrefixWriter(os.Stderr, j),
+		}
+
+		stdout = append(stdout, prefixed[0])
+		stderr = append(stderr, prefixed[1])
 	}
 
 	j.LogFilepath, err = e.ResolveJobLogFilepath(j, renderState)
@@ -226,69 +545,160 @@ func (e *Executor) RunJob(ctx context.Context, j *Job) (err error) {
 		return
 	}
 
-	logFile, err = os.Create(j.LogFilepath)
+	j.Directory, err = e.ResolveJobDirectory(j, renderState)
 	if err != nil {
-		err = errors.Wrapf(err,
-			"failed to create file for logging %s",
-			j.LogFilepath)
 		return
 	}
-	defer logFile.Close()
-
-	stdout = append(stdout, logFile)
-	stderr = append(stderr, logFile)
 
-	j.Directory, err = e.ResolveJobDirectory(j, renderState)
+	j.Env, err = e.ResolveJobEnv(j, renderState)
 	if err != nil {
 		return
 	}
 
-	j.Env, err = e.ResolveJobEnv(j, renderState)
+	j.Run, err = e.ResolveJobRun(j, renderState)
 	if err != nil {
 		return
 	}
 
-	j.Run, err = e.ResolveJobRun(j, renderState)
+	j.Command, err = e.ResolveJobCommand(j, renderState)
 	if err != nil {
 		return
 	}
 
-	if j.Run == "" {
-		goto END
+	if j.HasCommand() && len(j.Inputs) > 0 {
+		skip, err = e.checkCache(j)
+		if err != nil {
+			return
+		}
+
+		if skip {
+			e.notify(&Activity{
+				Type: ActivityCached,
+				Job:  j,
+			})
+			return
+		}
 	}
 
-	execution = &Execution{
-		Argv: []string{
-			"/bin/bash",
-			"-c",
-			j.Run,
-		},
-		Stdout:    io.MultiWriter(stdout...),
-		Stderr:    io.MultiWriter(stderr...),
-		Directory: j.Directory,
-		Env:       j.Env,
+	if j.HasCommand() && len(j.Creates) > 0 {
+		skip, err = e.IsUpToDate(j)
+		if err != nil {
+			return
+		}
+
+		if skip {
+			e.notify(&Activity{
+				Type: ActivityUpToDate,
+				Job:  j,
+			})
+			return
+		}
 	}
 
-	if e.config.OnJobStatusChange != nil {
-		e.config.OnJobStatusChange(&Activity{
-			Type: ActivityStarted,
-			Time: time.Now(),
-			Job:  j,
-		})
+	logFile, err = os.Create(j.LogFilepath)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to create file for logging %s",
+			j.LogFilepath)
+		return
 	}
+	defer logFile.Close()
 
-	err = execution.Run(ctx)
+	if e.config.Runtime.Output == OutputGrouped {
+		defer e.writeGroupedOutput(j)
+	}
+
+	stdout = append(stdout, logFile)
+	stderr = append(stderr, logFile)
+
+	if !j.HasCommand() {
+		goto END
+	}
+
+	e.notify(&Activity{
+		Type: ActivityStarted,
+		Job:  j,
+	})
+
+	for attempt = 1; ; attempt++ {
+		if j.Retries > 0 {
+			fmt.Fprintf(logFile, "==> attempt %d/%d\n",
+				attempt, j.Retries+1)
+		}
 
-	j.StartTime = &execution.StartTime
-	j.EndTime = &execution.EndTime
+		output.Reset()
+		execution = &Execution{
+			Argv:      e.JobArgv(j),
+			Runner:    e.jobRunner(j),
+			Stdout:    io.MultiWriter(stdout...),
+			Stderr:    io.MultiWriter(stderr...),
+			Directory: j.Directory,
+			Env:       j.Env,
+		}
+
+		timedOut, err = runExecution(ctx, execution, j.Timeout)
+		j.executed = true
+
+		for _, w := range prefixed {
+			w.Flush()
+		}
+
+		if attempt == 1 {
+			j.StartTime = &execution.StartTime
+		}
+		j.EndTime = &execution.EndTime
+		j.ExitCode = execution.ExitCode
+
+		if err == nil || attempt > j.Retries || ctx.Err() != nil {
+			break
+		}
+
+		e.notify(&Activity{
+			Type:    ActivityRetrying,
+			Job:     j,
+			Attempt: attempt,
+		})
+
+		select {
+		case <-time.After(j.RetryDelayFor(attempt)):
+		case <-ctx.Done():
+		}
+	}
 
 	if err != nil {
 		err = errors.Wrapf(err, "command execution failed")
 
-		if e.config.OnJobStatusChange != nil {
-			e.config.OnJobStatusChange(&Activity{
+		switch {
+		case ctx.Err() == context.DeadlineExceeded:
+			timeout, fromRuntime := e.executionTimeout(ctx)
+			if fromRuntime {
+				err = errors.Errorf("execution timed out after %s",
+					timeout)
+			} else {
+				err = errors.Errorf("execution reached the deadline of its context after %s",
+					timeout)
+			}
+			e.notify(&Activity{
+				Type:    ActivityTimeout,
+				Job:     j,
+				Timeout: timeout,
+			})
+		case ctx.Err() != nil:
+			e.notify(&Activity{
+				Type: ActivityAborted,
+				Job:  j,
+			})
+		case timedOut:
+			err = errors.Errorf("command timed out after %s",
+				j.Timeout)
+			e.notify(&Activity{
+				Type:    ActivityTimeout,
+				Job:     j,
+				Timeout: j.Timeout,
+			})
+		default:
+			e.notify(&Activity{
 				Type: ActivityErrored,
-				Time: time.Now(),
 				Job:  j,
 			})
 		}
@@ -296,22 +706,110 @@ func (e *Executor) RunJob(ctx context.Context, j *Job) (err error) {
 		return
 	}
 
-	j.Output = strings.TrimSpace(output.String())
+	// function jobs set their output themselves
+	if j.Func == nil {
+		j.Output = strings.TrimSpace(output.String())
+	}
 
-END:
-	if e.config.OnJobStatusChange != nil {
-		e.config.OnJobStatusChange(&Activity{
-			Type: ActivitySuccess,
-			Time: time.Now(),
-			Job:  j,
+	if len(j.Inputs) > 0 {
+		err = e.cache.Store(j.Id, &CacheEntry{
+			Key:    j.CacheKey,
+			Output: j.Output,
+			Time:   time.Now(),
 		})
+		if err != nil {
+			return
+		}
 	}
 
+END:
+	e.notify(&Activity{
+		Type: ActivitySuccess,
+		Job:  j,
+	})
+
 	return
 }
 
-func (e *Executor) CreateWalkFunc(ctx context.Context) dag.WalkFunc {
-	return func(v dag.Vertex) error {
+// jobRunner retrieves the runner that executes a job.
+func (e *Executor) jobRunner(j *Job) Runner {
+	if j.Func != nil {
+		return &funcRunner{
+			job:  j,
+			jobs: e.jobsMap,
+		}
+	}
+
+	return e.runners[j.runnerName()]
+}
+
+// executionTimeout retrieves the limit that made `ctx` exceed
+// its deadline: Runtime.Timeout if that's what set it, or
+// otherwise how long after the start of the execution the
+// deadline of the context given to Execute was.
+func (e *Executor) executionTimeout(ctx context.Context) (timeout time.Duration, fromRuntime bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+
+	timeout = e.config.Runtime.Timeout
+	if timeout > 0 && !deadline.Before(e.startTime.Add(timeout)) {
+		fromRuntime = true
+		return
+	}
+
+	timeout = deadline.Sub(e.startTime).Round(time.Millisecond)
+	return
+}
+
+// runExecution runs the execution tying it to a context
+// that expires after `timeout` (if non-zero), indicating
+// whether a deadline was the reason of a failure.
+func runExecution(ctx context.Context, execution *Execution, timeout time.Duration) (timedOut bool, err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	err = execution.Run(ctx)
+	timedOut = err != nil && ctx.Err() == context.DeadlineExceeded
+
+	return
+}
+
+// notify records the status transition of a job,
+// persisting the state of the run when the job finishes,
+// and forwards it to the OnJobStatusChange callback if one
+// has been configured.
+func (e *Executor) notify(a *Activity) {
+	a.Time = time.Now()
+	a.Job.Status = a.Type
+
+	switch a.Type {
+	case ActivityStarted, ActivityQueued, ActivityRetrying:
+	default:
+		e.saveJobState(a.Job)
+	}
+
+	if e.scheduler != nil {
+		a.Running, a.Queued = e.scheduler.Stats()
+	}
+
+	if e.config.OnJobStatusChange != nil {
+		e.config.OnJobStatusChange(a)
+	}
+}
+
+// CreateWalkFunc creates the callback to be executed
+// for each vertex of the graph. Jobs are only started
+// while `ctx` is not done and once the scheduler grants
+// them a slot and the resources they use.
+// `stop` is invoked whenever a job fails.
+func (e *Executor) CreateWalkFunc(ctx context.Context, stop func()) dag.WalkFunc {
+	return func(v dag.Vertex) (err error) {
 		job, ok := v.(*Job)
 		if !ok {
 			return errors.Errorf("vertex not a job")
@@ -321,20 +819,90 @@ func (e *Executor) CreateWalkFunc(ctx context.Context) dag.WalkFunc {
 			return nil
 		}
 
-		return e.RunJob(ctx, job)
+		if e.restored[job.Id] {
+			e.notify(&Activity{
+				Type: ActivityRestored,
+				Job:  job,
+			})
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			e.notify(&Activity{
+				Type: ActivityAborted,
+				Job:  job,
+			})
+			err = errors.Wrapf(ctx.Err(), "job not started")
+			return
+		}
+
+		err = e.scheduler.Acquire(ctx, e.jobsIndex[job.Id], job.Uses, func() {
+			e.notify(&Activity{
+				Type: ActivityQueued,
+				Job:  job,
+			})
+		})
+		if err != nil {
+			e.notify(&Activity{
+				Type: ActivityAborted,
+				Job:  job,
+			})
+			err = errors.Wrapf(err, "job not started")
+			return
+		}
+		defer e.scheduler.Release(job.Uses)
+
+		err = e.RunJob(ctx, job)
+		if err != nil {
+			stop()
+		}
+
+		return
 	}
 }
 
 // TraverseAndExecute goes through the graph
 // provided and starts the execution of the jobs.
+//
+// When a job fails, only the jobs that depend on it
+// are left out: independent branches keep getting
+// started and running jobs are allowed to finish.
+// In fail-fast mode no other jobs get started and the
+// running ones are killed (the ones that never started
+// are reported as aborted), while keep-going mode
+// reports the dependents of the failed jobs as skipped.
 func (e *Executor) TraverseAndExecute(ctx context.Context, g *dag.AcyclicGraph) (err error) {
+	if e.config.Runtime.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+
+		ctx, cancelTimeout = context.WithTimeout(ctx, e.config.Runtime.Timeout)
+		defer cancelTimeout()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stop := func() {
+		if e.config.Runtime.FailFast {
+			cancel()
+		}
+	}
+
 	w := &dag.Walker{
-		Callback: e.CreateWalkFunc(ctx),
+		Callback: e.CreateWalkFunc(ctx, stop),
 	}
 
 	w.Update(g)
 
 	err = w.Wait()
+	if ctx.Err() != nil {
+		e.abortPending(g)
+	}
+
+	if e.config.Runtime.KeepGoing {
+		e.skipPending(g)
+	}
+
 	if err != nil {
 		err = errors.Wrapf(err,
 			"execution of jobs failed")
@@ -343,3 +911,46 @@ func (e *Executor) TraverseAndExecute(ctx context.Context, g *dag.AcyclicGraph)
 
 	return
 }
+
+// abortPending reports as aborted every job from the
+// graph that never got the chance of being started.
+func (e *Executor) abortPending(g *dag.AcyclicGraph) {
+	for _, job := range e.config.Jobs {
+		if !g.HasVertex(job) || job.Status != ActivityUnknown {
+			continue
+		}
+
+		e.notify(&Activity{
+			Type: ActivityAborted,
+			Job:  job,
+		})
+	}
+}
+
+// skipPending reports as skipped every job from the
+// graph that didn't run because one of its dependencies
+// failed.
+func (e *Executor) skipPending(g *dag.AcyclicGraph) {
+	for _, job := range e.config.Jobs {
+		if !g.HasVertex(job) || job.Status != ActivityUnknown {
+			continue
+		}
+
+		e.notify(&Activity{
+			Type: ActivitySkipped,
+			Job:  job,
+		})
+	}
+}
+
+// FailedJobs lists the ids of the jobs that errored
+// or timed out in the last execution.
+func (e *Executor) FailedJobs() (res []string) {
+	for _, job := range e.config.Jobs {
+		if job.Status == ActivityErrored || job.Status == ActivityTimeout {
+			res = append(res, job.Id)
+		}
+	}
+
+	return
+}
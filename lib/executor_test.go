@@ -1,7 +1,10 @@
 package lib
 
 import (
+	"context"
+	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -167,3 +170,342 @@ func TestResolveEnvironment(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteFailureModes(t *testing.T) {
+	var testCases = []struct {
+		desc     string
+		runtime  Runtime
+		expected map[string]ActivityType
+		message  string
+	}{
+		{
+			desc: "default runs independent branches",
+			expected: map[string]ActivityType{
+				"fail":       ActivityErrored,
+				"slow":       ActivitySuccess,
+				"after-slow": ActivitySuccess,
+				"after-fail": ActivityUnknown,
+			},
+		},
+		{
+			desc:    "fail-fast kills running jobs",
+			runtime: Runtime{FailFast: true},
+			expected: map[string]ActivityType{
+				"fail":       ActivityErrored,
+				"slow":       ActivityAborted,
+				"after-slow": ActivityAborted,
+				"after-fail": ActivityAborted,
+			},
+		},
+		{
+			desc:    "keep-going skips dependents of failed jobs",
+			runtime: Runtime{KeepGoing: true},
+			expected: map[string]ActivityType{
+				"fail":       ActivityErrored,
+				"slow":       ActivitySuccess,
+				"after-slow": ActivitySuccess,
+				"after-fail": ActivitySkipped,
+			},
+			message: "(fail)",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			cfg := &Config{
+				Runtime: tc.runtime,
+				Jobs: []*Job{
+					{Id: "slow", Run: "sleep 1"},
+					{Id: "fail", Run: "sleep 0.2; exit 1"},
+					{Id: "after-slow", Run: "true", DependsOn: []string{"slow"}},
+					{Id: "after-fail", Run: "true", DependsOn: []string{"fail"}},
+				},
+			}
+			cfg.Runtime.LogsDirectory = t.TempDir()
+
+			e, err := New(cfg)
+			require.NoError(t, err)
+
+			err = e.Execute(context.Background())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.message)
+
+			for _, job := range cfg.Jobs {
+				assert.Equal(t,
+					ActivityMapping[tc.expected[job.Id]],
+					ActivityMapping[job.Status], job.Id)
+			}
+		})
+	}
+}
+
+func TestRetryDelayFor(t *testing.T) {
+	var testCases = []struct {
+		desc     string
+		job      Job
+		attempt  int
+		expected time.Duration
+	}{
+		{
+			desc:     "no delay",
+			job:      Job{},
+			attempt:  1,
+			expected: 0,
+		},
+		{
+			desc:     "constant delay without backoff",
+			job:      Job{RetryDelay: time.Second},
+			attempt:  3,
+			expected: time.Second,
+		},
+		{
+			desc:     "first retry isn't affected by backoff",
+			job:      Job{RetryDelay: time.Second, RetryBackoff: 2},
+			attempt:  1,
+			expected: time.Second,
+		},
+		{
+			desc:     "exponential backoff",
+			job:      Job{RetryDelay: time.Second, RetryBackoff: 2},
+			attempt:  3,
+			expected: 4 * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.job.RetryDelayFor(tc.attempt))
+		})
+	}
+}
+
+func TestRunJobRetries(t *testing.T) {
+	var (
+		dir      = t.TempDir()
+		attempts = []int{}
+		job      = &Job{
+			Id:      "flaky",
+			Run:     "echo run >> " + dir + "/count; [ $(wc -l < " + dir + "/count) -ge 3 ]",
+			Retries: 3,
+		}
+		cfg = &Config{
+			Runtime: Runtime{LogsDirectory: dir},
+			Jobs:    []*Job{job},
+			OnJobStatusChange: func(a *Activity) {
+				if a.Type == ActivityRetrying {
+					attempts = append(attempts, a.Attempt)
+				}
+			},
+		}
+	)
+
+	e, err := New(cfg)
+	require.NoError(t, err)
+
+	err = e.Execute(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{1, 2}, attempts)
+	assert.Equal(t, ActivitySuccess, job.Status)
+
+	logs, err := ioutil.ReadFile(job.LogFilepath)
+	require.NoError(t, err)
+	assert.Contains(t, string(logs), "==> attempt 3/4")
+}
+
+func TestExecuteTimeouts(t *testing.T) {
+	var testCases = []struct {
+		desc     string
+		runtime  Runtime
+		deadline time.Duration
+		job      *Job
+		expected time.Duration
+	}{
+		{
+			desc:     "job timeout",
+			job:      &Job{Id: "hang", Run: "sleep 10", Timeout: 200 * time.Millisecond},
+			expected: 200 * time.Millisecond,
+		},
+		{
+			desc:     "global timeout",
+			runtime:  Runtime{Timeout: 300 * time.Millisecond},
+			job:      &Job{Id: "hang", Run: "sleep 10"},
+			expected: 300 * time.Millisecond,
+		},
+		{
+			desc:     "context deadline",
+			deadline: 250 * time.Millisecond,
+			job:      &Job{Id: "hang", Run: "sleep 10"},
+			expected: 250 * time.Millisecond,
+		},
+		{
+			desc:     "context deadline before the global timeout",
+			runtime:  Runtime{Timeout: 10 * time.Second},
+			deadline: 250 * time.Millisecond,
+			job:      &Job{Id: "hang", Run: "sleep 10"},
+			expected: 250 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var limit time.Duration
+
+			cfg := &Config{
+				Runtime: tc.runtime,
+				Jobs:    []*Job{tc.job},
+				OnJobStatusChange: func(a *Activity) {
+					if a.Type == ActivityTimeout {
+						limit = a.Timeout
+					}
+				},
+			}
+			cfg.Runtime.LogsDirectory = t.TempDir()
+
+			e, err := New(cfg)
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			if tc.deadline > 0 {
+				var cancel context.CancelFunc
+
+				ctx, cancel = context.WithTimeout(ctx, tc.deadline)
+				defer cancel()
+			}
+
+			err = e.Execute(ctx)
+			require.Error(t, err)
+			assert.NotContains(t, err.Error(), "after 0s")
+
+			assert.Equal(t, ActivityTimeout, tc.job.Status)
+			assert.InDelta(t, float64(tc.expected), float64(limit),
+				float64(10*time.Millisecond))
+			assert.True(t, tc.job.EndTime.Sub(*tc.job.StartTime) < 5*time.Second)
+		})
+	}
+}
+
+func TestResolveJobCommand(t *testing.T) {
+	var testCases = []struct {
+		desc        string
+		job         *Job
+		state       *RenderState
+		expected    []string
+		shouldError bool
+	}{
+		{
+			desc:        "nil",
+			shouldError: true,
+		},
+		{
+			desc:     "no command",
+			job:      &Job{},
+			state:    &RenderState{},
+			expected: nil,
+		},
+		{
+			desc: "templated element by element",
+			job: &Job{
+				Command: []string{"echo", "{{ .Jobs.Job1.Output }}", "a b"},
+			},
+			state: &RenderState{
+				Jobs: map[string]*Job{
+					"Job1": {Output: "lol; rm -rf /"},
+				},
+			},
+			expected: []string{"echo", "lol; rm -rf /", "a b"},
+		},
+		{
+			desc: "invalid template",
+			job: &Job{
+				Command: []string{"echo", "{{ .Jobs"},
+			},
+			state:       &RenderState{},
+			shouldError: true,
+		},
+	}
+
+	e := Executor{}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			actual, err := e.ResolveJobCommand(tc.job, tc.state)
+			if tc.shouldError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestJobArgv(t *testing.T) {
+	var testCases = []struct {
+		desc     string
+		runtime  Runtime
+		job      *Job
+		expected []string
+	}{
+		{
+			desc:     "default shell",
+			job:      &Job{Run: "echo a"},
+			expected: []string{"/bin/bash", "-c", "echo a"},
+		},
+		{
+			desc:     "runtime shell",
+			runtime:  Runtime{Shell: []string{"sh", "-eu", "-c"}},
+			job:      &Job{Run: "echo a"},
+			expected: []string{"sh", "-eu", "-c", "echo a"},
+		},
+		{
+			desc:     "job shell takes precedence",
+			runtime:  Runtime{Shell: []string{"sh", "-c"}},
+			job:      &Job{Run: "echo a", Shell: []string{"zsh", "-c"}},
+			expected: []string{"zsh", "-c", "echo a"},
+		},
+		{
+			desc:     "command bypasses the shell",
+			runtime:  Runtime{Shell: []string{"sh", "-c"}},
+			job:      &Job{Command: []string{"echo", "a"}, Shell: []string{"zsh", "-c"}},
+			expected: []string{"echo", "a"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			e := Executor{config: &Config{Runtime: tc.runtime}}
+			assert.Equal(t, tc.expected, e.JobArgv(tc.job))
+		})
+	}
+}
+
+func TestExecuteCommand(t *testing.T) {
+	var (
+		dir = t.TempDir()
+
+		version = &Job{Id: "version", Run: "echo '1.0 beta'", CaptureOutput: true, Shell: []string{"sh", "-eu", "-c"}}
+		release = &Job{
+			Id:            "release",
+			Command:       []string{"printf", "%s|", "{{ .Jobs.version.Output }}", "$HOME"},
+			CaptureOutput: true,
+			DependsOn:     []string{"version"},
+		}
+	)
+
+	e, err := New(&Config{
+		Runtime: Runtime{LogsDirectory: dir},
+		Jobs:    []*Job{version, release},
+	})
+	require.NoError(t, err)
+	require.NoError(t, e.Execute(context.Background()))
+
+	assert.Equal(t, "1.0 beta|$HOME|", release.Output)
+
+	_, err = New(&Config{
+		Runtime: Runtime{LogsDirectory: dir},
+		Jobs:    []*Job{{Id: "both", Run: "true", Command: []string{"true"}}},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package lib
+
+import (
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// IsUpToDate tells, make-style, whether the files that a job
+// creates are all newer than the files it's built from, in
+// which case there's no need to execute it.
+// A job is never up to date if any of the jobs it depends on
+// actually executed a command in this run, or if any of its `Creates` is missing.
+// It must be called after the job fields have been resolved.
+func (e *Executor) IsUpToDate(j *Job) (fresh bool, err error) {
+	var (
+		oldestTarget time.Time
+		newestSource time.Time
+		finfo        os.FileInfo
+		matches      []string
+	)
+
+	if len(j.Creates) == 0 {
+		return
+	}
+
+	for _, dep := range j.DependsOn {
+		if e.jobsMap[dep].executed {
+			return
+		}
+	}
+
+	for _, target := range j.Creates {
+		finfo, err = os.Stat(j.resolvePath(target))
+		if err != nil {
+			if os.IsNotExist(err) {
+				err = nil
+				return
+			}
+
+			err = errors.Wrapf(err,
+				"failed to stat file %s", target)
+			return
+		}
+
+		if oldestTarget.IsZero() || finfo.ModTime().Before(oldestTarget) {
+			oldestTarget = finfo.ModTime()
+		}
+	}
+
+	for _, pattern := range j.Sources {
+		matches, err = expandGlob(j.resolvePath(pattern))
+		if err != nil {
+			err = errors.Wrapf(err,
+				"invalid source pattern %s", pattern)
+			return
+		}
+
+		for _, source := range matches {
+			finfo, err = os.Stat(source)
+			if err != nil {
+				err = errors.Wrapf(err,
+					"failed to stat file %s", source)
+				return
+			}
+
+			if finfo.ModTime().After(newestSource) {
+				newestSource = finfo.ModTime()
+			}
+		}
+	}
+
+	fresh = oldestTarget.After(newestSource)
+	return
+}
@@ -0,0 +1,108 @@
+package lib
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsUpToDate(t *testing.T) {
+	var (
+		dir = t.TempDir()
+		now = time.Now()
+	)
+
+	touch := func(name string, age time.Duration) {
+		file := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(file, nil, 0644))
+		require.NoError(t, os.Chtimes(file, now.Add(-age), now.Add(-age)))
+	}
+
+	touch("old.c", 2*time.Hour)
+	touch("new.c", 1*time.Minute)
+	touch("app", 1*time.Hour)
+
+	var testCases = []struct {
+		desc      string
+		job       *Job
+		upstream  ActivityType
+		executed  bool
+		expected  bool
+		dependent bool
+	}{
+		{
+			desc:     "no creates",
+			job:      &Job{Sources: []string{"old.c"}},
+			expected: false,
+		},
+		{
+			desc:     "target newer than sources",
+			job:      &Job{Creates: []string{"app"}, Sources: []string{"old.c"}},
+			expected: true,
+		},
+		{
+			desc:     "target older than a source",
+			job:      &Job{Creates: []string{"app"}, Sources: []string{"*.c"}},
+			expected: false,
+		},
+		{
+			desc:     "missing target",
+			job:      &Job{Creates: []string{"app", "lib.so"}, Sources: []string{"old.c"}},
+			expected: false,
+		},
+		{
+			desc:     "target without sources",
+			job:      &Job{Creates: []string{"app"}},
+			expected: true,
+		},
+		{
+			desc:      "upstream executed",
+			job:       &Job{Creates: []string{"app"}, Sources: []string{"old.c"}},
+			upstream:  ActivitySuccess,
+			executed:  true,
+			dependent: true,
+			expected:  false,
+		},
+		{
+			desc:      "upstream without a command",
+			job:       &Job{Creates: []string{"app"}, Sources: []string{"old.c"}},
+			upstream:  ActivitySuccess,
+			dependent: true,
+			expected:  true,
+		},
+		{
+			desc:      "upstream up to date",
+			job:       &Job{Creates: []string{"app"}, Sources: []string{"old.c"}},
+			upstream:  ActivityUpToDate,
+			dependent: true,
+			expected:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			upstream := &Job{Id: "upstream", Status: tc.upstream, executed: tc.executed}
+
+			tc.job.Id = "job"
+			tc.job.Directory = dir
+			if tc.dependent {
+				tc.job.DependsOn = []string{"upstream"}
+			}
+
+			e, err := New(&Config{
+				Runtime: Runtime{LogsDirectory: dir},
+				Jobs:    []*Job{upstream, tc.job},
+			})
+			require.NoError(t, err)
+
+			fresh, err := e.IsUpToDate(tc.job)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, fresh)
+		})
+	}
+}
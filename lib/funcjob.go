@@ -0,0 +1,122 @@
+package lib
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// JobFunc is a job implemented as a Go function. The output
+// it returns becomes the `.Output` of the job.
+// The context gets cancelled when the job gets aborted or
+// times out, in which case the function must return promptly
+// as, unlike processes, it can't be killed.
+type JobFunc func(ctx context.Context, jc *JobContext) (output string, err error)
+
+// JobContext is what a JobFunc gets to know about the
+// execution it carries out.
+type JobContext struct {
+	// Job is the job being executed, with its fields
+	// already resolved.
+	Job *Job
+
+	// Jobs maps the id of every job to the job itself so
+	// that the results of dependencies can be inspected.
+	Jobs map[string]*Job
+
+	// Args is the templated Command of the job.
+	Args []string
+
+	Directory string
+	Env       map[string]string
+
+	// Stdout and Stderr are where logs should be written
+	// to so that they end up with the logs of the job.
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// AddFuncJob registers a job implemented as a Go function,
+// returning it so that the rest of its fields (DependsOn,
+// Command, Env, ...) can be set before creating the Executor.
+func (c *Config) AddFuncJob(id string, fn JobFunc) (j *Job) {
+	j = &Job{
+		Id:   id,
+		Func: fn,
+	}
+
+	c.Jobs = append(c.Jobs, j)
+	return
+}
+
+// funcRunner executes a JobFunc in-process.
+type funcRunner struct {
+	job  *Job
+	jobs map[string]*Job
+}
+
+type funcProcess struct {
+	fn     JobFunc
+	jc     *JobContext
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	output string
+	err    error
+}
+
+func (r *funcRunner) Prepare(execution *Execution) (p Process, err error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p = &funcProcess{
+		fn:     r.job.Func,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		jc: &JobContext{
+			Job:       r.job,
+			Jobs:      r.jobs,
+			Args:      execution.Argv,
+			Directory: execution.Directory,
+			Env:       execution.Env,
+			Stdout:    execution.Stdout,
+			Stderr:    execution.Stderr,
+		},
+	}
+	return
+}
+
+func (p *funcProcess) Start() (err error) {
+	go func() {
+		defer close(p.done)
+		defer func() {
+			if r := recover(); r != nil {
+				p.err = errors.Errorf("function panicked: %v", r)
+			}
+		}()
+
+		p.output, p.err = p.fn(p.ctx, p.jc)
+	}()
+
+	return
+}
+
+func (p *funcProcess) Wait() (exitCode int, err error) {
+	<-p.done
+	p.cancel()
+
+	if p.err != nil {
+		exitCode = defaultFailedExitCode
+		err = p.err
+		return
+	}
+
+	p.jc.Job.Output = p.output
+	return
+}
+
+func (p *funcProcess) Kill() (err error) {
+	p.cancel()
+	return
+}
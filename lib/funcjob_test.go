@@ -0,0 +1,117 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteFuncJobs(t *testing.T) {
+	var (
+		cfg        = &Config{Runtime: Runtime{LogsDirectory: t.TempDir(), KeepGoing: true}}
+		activities = map[string][]ActivityType{}
+		jc         *JobContext
+		lock       sync.Mutex
+	)
+
+	cfg.OnJobStatusChange = func(a *Activity) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		activities[a.Job.Id] = append(activities[a.Job.Id], a.Type)
+	}
+
+	cfg.Jobs = append(cfg.Jobs, &Job{Id: "version", Run: "echo 1.0", CaptureOutput: true})
+
+	build := cfg.AddFuncJob("build", func(ctx context.Context, c *JobContext) (string, error) {
+		jc = c
+		fmt.Fprintln(c.Stdout, "building")
+		return "app-" + c.Args[0], nil
+	})
+	build.Command = []string{"{{ .Jobs.version.Output }}"}
+	build.Env = map[string]string{"MODE": "release"}
+	build.DependsOn = []string{"version"}
+
+	cfg.Jobs = append(cfg.Jobs, &Job{
+		Id:            "publish",
+		Run:           "echo publishing {{ .Jobs.build.Output }}",
+		CaptureOutput: true,
+		DependsOn:     []string{"build"},
+	})
+
+	failing := cfg.AddFuncJob("failing", func(ctx context.Context, c *JobContext) (string, error) {
+		return "", fmt.Errorf("boom")
+	})
+	failing.Retries = 1
+
+	panicking := cfg.AddFuncJob("panicking", func(ctx context.Context, c *JobContext) (string, error) {
+		panic("oops")
+	})
+
+	e, err := New(cfg)
+	require.NoError(t, err)
+	require.Error(t, e.Execute(context.Background()))
+
+	assert.Equal(t, ActivitySuccess, build.Status)
+	assert.Equal(t, "app-1.0", build.Output)
+	assert.Equal(t, []string{"1.0"}, jc.Args)
+	assert.Equal(t, "release", jc.Env["MODE"])
+	assert.Equal(t, "1.0", jc.Jobs["version"].Output)
+	assert.Equal(t, []ActivityType{ActivityStarted, ActivitySuccess}, activities["build"])
+
+	logs, err := ioutil.ReadFile(build.LogFilepath)
+	require.NoError(t, err)
+	assert.Equal(t, "building\n", string(logs))
+
+	assert.Equal(t, "publishing app-1.0", cfg.Jobs[2].Output)
+
+	assert.Equal(t, ActivityErrored, failing.Status)
+	assert.Equal(t, []ActivityType{ActivityStarted, ActivityRetrying, ActivityErrored}, activities["failing"])
+
+	assert.Equal(t, ActivityErrored, panicking.Status)
+	assert.Equal(t, []string{"failing", "panicking"}, e.FailedJobs())
+}
+
+func TestExecuteFuncJobTimeout(t *testing.T) {
+	cfg := &Config{Runtime: Runtime{LogsDirectory: t.TempDir()}}
+
+	job := cfg.AddFuncJob("slow", func(ctx context.Context, c *JobContext) (string, error) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(5 * time.Second):
+			return "done", nil
+		}
+	})
+	job.Timeout = 100 * time.Millisecond
+
+	e, err := New(cfg)
+	require.NoError(t, err)
+	require.Error(t, e.Execute(context.Background()))
+
+	assert.Equal(t, ActivityTimeout, job.Status)
+	assert.Empty(t, job.Output)
+}
+
+func TestNewInvalidFuncJob(t *testing.T) {
+	fn := func(ctx context.Context, c *JobContext) (string, error) {
+		return "", nil
+	}
+
+	for _, job := range []*Job{
+		{Id: "run", Func: fn, Run: "true"},
+		{Id: "runner", Func: fn, Runner: LocalRunnerName},
+	} {
+		_, err := New(&Config{
+			Runtime: Runtime{LogsDirectory: t.TempDir()},
+			Jobs:    []*Job{job},
+		})
+		assert.Error(t, err, job.Id)
+	}
+}
@@ -0,0 +1,104 @@
+package lib
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// expandGlob returns the paths matching `pattern`. Besides
+// what `filepath.Match` supports, a `**` path element matches
+// any number (including zero) of directories, e.g.
+// `src/**/*.c` matches `src/main.c` and `src/a/b/util.c`.
+func expandGlob(pattern string) (matches []string, err error) {
+	if !strings.Contains(pattern, "**") {
+		matches, err = filepath.Glob(pattern)
+		return
+	}
+
+	var (
+		elems = strings.Split(filepath.ToSlash(pattern), "/")
+		root  []string
+	)
+
+	for _, elem := range elems {
+		_, err = filepath.Match(elem, "")
+		if err != nil {
+			return
+		}
+	}
+
+	for len(elems) > 0 && !hasMeta(elems[0]) {
+		root = append(root, elems[0])
+		elems = elems[1:]
+	}
+
+	rootDir := filepath.FromSlash(strings.Join(root, "/"))
+	if len(root) == 1 && root[0] == "" {
+		rootDir = string(filepath.Separator)
+	} else if len(root) == 0 {
+		rootDir = "."
+	}
+
+	err = filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if path == rootDir && os.IsNotExist(walkErr) {
+				return filepath.SkipDir
+			}
+			return walkErr
+		}
+
+		rel, err := filepath.Rel(rootDir, path)
+		if err != nil {
+			return err
+		}
+
+		var parts []string
+		if rel != "." {
+			parts = strings.Split(filepath.ToSlash(rel), "/")
+		}
+
+		if matchElems(elems, parts) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to walk directory %s", rootDir)
+		return
+	}
+
+	return
+}
+
+// matchElems tells whether the path elements in `parts`
+// match the pattern elements in `pattern`.
+func matchElems(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchElems(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(parts) == 0 {
+		return false
+	}
+
+	matched, _ := filepath.Match(pattern[0], parts[0])
+	return matched && matchElems(pattern[1:], parts[1:])
+}
+
+func hasMeta(elem string) bool {
+	return strings.ContainsAny(elem, `*?[\`)
+}
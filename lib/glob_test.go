@@ -0,0 +1,85 @@
+package lib
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandGlob(t *testing.T) {
+	var dir = t.TempDir()
+
+	for _, file := range []string{
+		"main.c",
+		"main.h",
+		"src/util.c",
+		"src/a/b/deep.c",
+		"src/a/b/deep.h",
+	} {
+		path := filepath.Join(dir, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, nil, 0644))
+	}
+
+	var testCases = []struct {
+		desc     string
+		pattern  string
+		expected []string
+		err      bool
+	}{
+		{
+			desc:     "single directory",
+			pattern:  "*.c",
+			expected: []string{"main.c"},
+		},
+		{
+			desc:     "any depth",
+			pattern:  "**/*.c",
+			expected: []string{"main.c", "src/a/b/deep.c", "src/util.c"},
+		},
+		{
+			desc:     "any depth under a directory",
+			pattern:  "src/**/*.h",
+			expected: []string{"src/a/b/deep.h"},
+		},
+		{
+			desc:     "between directories",
+			pattern:  "src/**/b/*.c",
+			expected: []string{"src/a/b/deep.c"},
+		},
+		{
+			desc:     "missing directory",
+			pattern:  "missing/**/*.c",
+			expected: nil,
+		},
+		{
+			desc:    "bad pattern",
+			pattern: "**/[",
+			err:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			matches, err := expandGlob(filepath.Join(dir, tc.pattern))
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var rel []string
+			for _, match := range matches {
+				r, err := filepath.Rel(dir, match)
+				require.NoError(t, err)
+				rel = append(rel, filepath.ToSlash(r))
+			}
+			assert.Equal(t, tc.expected, rel)
+		})
+	}
+}
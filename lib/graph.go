@@ -9,7 +9,10 @@ import (
 // a dumb root aiming at providing the
 // biggest possible parallelism to a series
 // of job builds.
-func BuildDependencyGraph(jobs []*Job) (g dag.AcyclicGraph, err error) {
+// If `targets` is not empty, the graph only
+// contains the jobs listed there and the
+// jobs they (transitively) depend on.
+func BuildDependencyGraph(jobs []*Job, targets []string) (g dag.AcyclicGraph, err error) {
 	var (
 		rootJob = &Job{
 			Id: "_root",
@@ -76,5 +79,113 @@ func BuildDependencyGraph(jobs []*Job) (g dag.AcyclicGraph, err error) {
 		return
 	}
 
+	if len(targets) == 0 {
+		return
+	}
+
+	err = pruneToTargets(&g, jobsMap, targets)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to select targets")
+		return
+	}
+
+	return
+}
+
+// pruneToTargets removes from the graph every job that
+// is neither a target nor a dependency of one.
+func pruneToTargets(g *dag.AcyclicGraph, jobsMap map[string]*Job, targets []string) (err error) {
+	var (
+		keep  = map[string]bool{"_root": true}
+		visit func(job *Job)
+	)
+
+	visit = func(job *Job) {
+		if keep[job.Id] {
+			return
+		}
+
+		keep[job.Id] = true
+		for _, dep := range job.DependsOn {
+			visit(jobsMap[dep])
+		}
+	}
+
+	for _, target := range targets {
+		job, present := jobsMap[target]
+		if !present {
+			err = errors.Errorf(
+				"target job %s does not exist",
+				target)
+			return
+		}
+
+		visit(job)
+	}
+
+	for id, job := range jobsMap {
+		if !keep[id] {
+			g.Remove(job)
+		}
+	}
+
+	return
+}
+
+// ExcludeJobs removes from the graph the jobs listed in
+// `ids` as well as every job that (transitively) depends
+// on them. The ids of the jobs that got removed only
+// as a consequence of depending on excluded ones are
+// returned.
+func ExcludeJobs(g *dag.AcyclicGraph, jobs []*Job, ids []string) (dependents []string, err error) {
+	var (
+		jobsMap  = map[string]*Job{}
+		skipped  = map[string]bool{}
+		excluded = map[string]bool{}
+		visit    func(v dag.Vertex)
+	)
+
+	for _, job := range jobs {
+		jobsMap[job.Id] = job
+	}
+
+	visit = func(v dag.Vertex) {
+		job := v.(*Job)
+		if excluded[job.Id] {
+			return
+		}
+
+		excluded[job.Id] = true
+		for _, dependent := range g.DownEdges(v).List() {
+			visit(dependent)
+		}
+	}
+
+	for _, id := range ids {
+		job, present := jobsMap[id]
+		if !present {
+			err = errors.Errorf(
+				"excluded job %s does not exist",
+				id)
+			return
+		}
+
+		skipped[id] = true
+		if g.HasVertex(job) {
+			visit(job)
+		}
+	}
+
+	for _, job := range jobs {
+		if !excluded[job.Id] {
+			continue
+		}
+
+		g.Remove(job)
+		if !skipped[job.Id] {
+			dependents = append(dependents, job.Id)
+		}
+	}
+
 	return
 }
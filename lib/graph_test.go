@@ -13,6 +13,7 @@ func TestBuildDependencyGraph(t *testing.T) {
 	var testCases = []struct {
 		desc       string
 		jobs       []*Job
+		targets    []string
 		expected   string
 		shouldFail bool
 	}{
@@ -125,6 +126,46 @@ job2
   job3
 job3`,
 		},
+		{
+			desc: "target pulls its dependencies",
+			jobs: []*Job{
+				{
+					Id: "job1",
+				},
+				{
+					Id: "job2",
+					DependsOn: []string{
+						"job1",
+					},
+				},
+				{
+					Id: "job3",
+					DependsOn: []string{
+						"job1",
+					},
+				},
+				{
+					Id: "job4",
+				},
+			},
+			targets: []string{"job2"},
+			expected: `
+_root
+  job1
+job1
+  job2
+job2`,
+		},
+		{
+			desc: "inexistent target",
+			jobs: []*Job{
+				{
+					Id: "job1",
+				},
+			},
+			targets:    []string{"job2"},
+			shouldFail: true,
+		},
 		{
 			desc: "cyclic dependency",
 			jobs: []*Job{
@@ -156,7 +197,7 @@ job3`,
 
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
-			graph, err = BuildDependencyGraph(tc.jobs)
+			graph, err = BuildDependencyGraph(tc.jobs, tc.targets)
 			if tc.shouldFail {
 				require.Error(t, err)
 				return
@@ -171,3 +212,79 @@ job3`,
 		})
 	}
 }
+
+func TestExcludeJobs(t *testing.T) {
+	var testCases = []struct {
+		desc       string
+		exclude    []string
+		expected   string
+		dependents []string
+		shouldFail bool
+	}{
+		{
+			desc: "nothing excluded",
+			expected: `
+_root
+  job1
+  job4
+job1
+  job2
+job2
+  job3
+job3
+job4`,
+		},
+		{
+			desc:    "leaf excluded",
+			exclude: []string{"job3"},
+			expected: `
+_root
+  job1
+  job4
+job1
+  job2
+job2
+job4`,
+		},
+		{
+			desc:    "dependents excluded",
+			exclude: []string{"job1"},
+			expected: `
+_root
+  job4
+job4`,
+			dependents: []string{"job2", "job3"},
+		},
+		{
+			desc:       "inexistent job",
+			exclude:    []string{"job5"},
+			shouldFail: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			jobs := []*Job{
+				{Id: "job1"},
+				{Id: "job2", DependsOn: []string{"job1"}},
+				{Id: "job3", DependsOn: []string{"job2"}},
+				{Id: "job4"},
+			}
+
+			graph, err := BuildDependencyGraph(jobs, nil)
+			require.NoError(t, err)
+
+			dependents, err := ExcludeJobs(&graph, jobs, tc.exclude)
+			if tc.shouldFail {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.dependents, dependents)
+			assert.Equal(t,
+				strings.Trim(tc.expected, "\n"),
+				strings.Trim(graph.String(), "\n"))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+const (
+	// OutputStream streams the output of the jobs as
+	// it gets written, prefixing each line with the id
+	// of the job (same as `Runtime.Stdout`).
+	OutputStream = "stream"
+
+	// OutputGrouped buffers the output of each job,
+	// writing it as a single block once the job
+	// finishes.
+	OutputGrouped = "grouped"
+)
+
+// writeJobBlock writes the combined output of a job that
+// has been executed, headed by its id, status and duration.
+func (e *Executor) writeJobBlock(buf *bytes.Buffer, j *Job) {
+	content, err := ioutil.ReadFile(j.LogFilepath)
+	if err != nil {
+		content = []byte(fmt.Sprintf(
+			"failed to read logs from %s: %s\n",
+			j.LogFilepath, err))
+	}
+
+	WriterMapping[j.Status].Fprintf(buf, "==> %s (%s in %s)",
+		j.Id,
+		ActivityMapping[j.Status],
+		j.EndTime.Sub(*j.StartTime).Round(time.Millisecond))
+	buf.WriteString("\n")
+
+	buf.Write(content)
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		buf.WriteString("\n")
+	}
+}
+
+// writeGroupedOutput writes the output of a job that has
+// been executed to the output of the executor as a single
+// block.
+func (e *Executor) writeGroupedOutput(j *Job) {
+	var buf bytes.Buffer
+
+	if j.StartTime == nil || j.EndTime == nil {
+		return
+	}
+
+	e.writeJobBlock(&buf, j)
+
+	e.outputLock.Lock()
+	defer e.outputLock.Unlock()
+
+	e.output.Write(buf.Bytes())
+}
+
+// writeFailures repeats the output of every job that
+// failed so that it can be found at the end of the
+// output of the execution.
+func (e *Executor) writeFailures() {
+	var (
+		buf    bytes.Buffer
+		failed = e.FailedJobs()
+	)
+
+	if len(failed) == 0 {
+		return
+	}
+
+	WriterMapping[ActivityErrored].Fprintf(&buf,
+		"==> failures (%d)", len(failed))
+	buf.WriteString("\n")
+
+	for _, id := range failed {
+		e.writeJobBlock(&buf, e.jobsMap[id])
+	}
+
+	e.outputLock.Lock()
+	defer e.outputLock.Unlock()
+
+	e.output.Write(buf.Bytes())
+}
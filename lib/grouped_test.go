@@ -0,0 +1,93 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteGroupedOutput(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		ui  = NewUi()
+	)
+
+	noColor := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = noColor })
+
+	// the status lines go to the same buffer, which is
+	// only safe as long as the lock is shared
+	ui.SetWriter(&buf)
+
+	e, err := New(&Config{
+		Runtime: Runtime{
+			LogsDirectory: t.TempDir(),
+			Output:        OutputGrouped,
+			KeepGoing:     true,
+		},
+		OnJobStatusChange: func(a *Activity) {
+			ui.WriteActivity(a)
+		},
+		OutputLock: &ui,
+		Jobs: []*Job{
+			{Id: "slow", Run: "for i in 1 2 3; do echo slow $i; sleep 0.1; done"},
+			{Id: "fail", Run: "echo out; echo err >&2; exit 1"},
+			{Id: "partial", Run: "printf partial", DependsOn: []string{"fail"}},
+		},
+	})
+	require.NoError(t, err)
+
+	e.output = &buf
+	require.Error(t, e.Execute(context.Background()))
+
+	output := buf.String()
+
+	assert.Contains(t, output, "==> slow (SUCCESS in ")
+	assert.Contains(t, output, "status=ERRORED")
+	assert.Contains(t, output, ")\nslow 1\nslow 2\nslow 3\n")
+	assert.NotContains(t, output, "==> partial")
+
+	failures := strings.Index(output, "==> failures (1)\n")
+	require.NotEqual(t, -1, failures)
+
+	assert.Equal(t, 2, strings.Count(output, "==> fail (ERRORED in "))
+	// stdout and stderr go through different pipes, so
+	// their relative order isn't guaranteed
+	assert.Contains(t, output[failures:], "\nout\n")
+	assert.Contains(t, output[failures:], "\nerr\n")
+	assert.NotContains(t, output[failures:], "slow")
+}
+
+func TestNewInvalidOutput(t *testing.T) {
+	var testCases = []struct {
+		desc    string
+		runtime Runtime
+	}{
+		{
+			desc:    "unknown mode",
+			runtime: Runtime{Output: "bogus"},
+		},
+		{
+			desc:    "grouped with stdout",
+			runtime: Runtime{Output: OutputGrouped, Stdout: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tc.runtime.LogsDirectory = t.TempDir()
+
+			_, err := New(&Config{
+				Runtime: tc.runtime,
+				Jobs:    []*Job{{Id: "a", Run: "true"}},
+			})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package lib
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// junitLogTailLines is the number of lines from the end
+	// of the logs of a failed job to include in the report.
+	junitLogTailLines = 50
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name          `xml:"testsuites"`
+	Suites  []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Skipped   int              `xml:"skipped,attr"`
+	Time      string           `xml:"time,attr"`
+	Timestamp string           `xml:"timestamp,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnitReport writes a JUnit XML document with one test
+// case per job of the execution graph.
+func (e *Executor) WriteJUnitReport(w io.Writer) (err error) {
+	suite := &junitTestSuite{
+		Name:      "cr",
+		Time:      seconds(e.endTime.Sub(e.startTime).Seconds()),
+		Timestamp: e.startTime.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, job := range e.config.Jobs {
+		if !e.graph.HasVertex(job) {
+			continue
+		}
+
+		testCase := &junitTestCase{
+			Name:      job.Id,
+			Classname: "cr",
+			Time:      seconds(0),
+		}
+
+		if job.StartTime != nil && job.EndTime != nil {
+			testCase.Time = seconds(job.EndTime.Sub(*job.StartTime).Seconds())
+		}
+
+		switch {
+		case job.Status == ActivityErrored:
+			testCase.Failure = &junitFailure{
+				Message:  fmt.Sprintf("exited with code %d", job.ExitCode),
+				Type:     "error",
+				Contents: tailFile(job.LogFilepath, junitLogTailLines),
+			}
+		case job.Status == ActivityTimeout:
+			testCase.Failure = &junitFailure{
+				Message:  "timed out",
+				Type:     "timeout",
+				Contents: tailFile(job.LogFilepath, junitLogTailLines),
+			}
+		case job.Status == ActivityAborted && job.StartTime != nil:
+			testCase.Failure = &junitFailure{
+				Message:  "aborted",
+				Type:     "aborted",
+				Contents: tailFile(job.LogFilepath, junitLogTailLines),
+			}
+		case job.Status == ActivityAborted:
+			testCase.Skipped = &junitSkipped{
+				Message: "aborted before starting",
+			}
+		case job.Status == ActivitySkipped:
+			testCase.Skipped = &junitSkipped{
+				Message: "a dependency failed",
+			}
+		case job.Status == ActivityUnknown:
+			testCase.Skipped = &junitSkipped{
+				Message: "never ran",
+			}
+		}
+
+		if testCase.Failure != nil {
+			suite.Failures++
+		}
+
+		if testCase.Skipped != nil {
+			suite.Skipped++
+		}
+
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	_, err = io.WriteString(w, xml.Header)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to write junit report")
+		return
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(&junitTestSuites{
+		Suites: []*junitTestSuite{suite},
+	})
+	if err != nil {
+		err = errors.Wrapf(err, "failed to write junit report")
+		return
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
+
+// tailFile retrieves the last `n` lines of a file. Errors
+// are ignored as it's only used for informative purposes.
+func tailFile(file string, n int) string {
+	if file == "" {
+		return ""
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+
+	return strings.Join(lines, "\n")
+}
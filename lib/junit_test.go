@@ -0,0 +1,49 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJUnitReport(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		report junitTestSuites
+	)
+
+	e, err := New(&Config{
+		Runtime: Runtime{LogsDirectory: t.TempDir(), KeepGoing: true},
+		Jobs: []*Job{
+			{Id: "ok", Run: "true"},
+			{Id: "fail", Run: "echo first; echo last; exit 3"},
+			{Id: "after-fail", Run: "true", DependsOn: []string{"fail"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Error(t, e.Execute(context.Background()))
+
+	require.NoError(t, e.WriteJUnitReport(&buf))
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+
+	require.Len(t, report.Suites, 1)
+	suite := report.Suites[0]
+
+	assert.Equal(t, 3, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	assert.Equal(t, 1, suite.Skipped)
+	require.Len(t, suite.TestCases, 3)
+
+	assert.Nil(t, suite.TestCases[0].Failure)
+	assert.Nil(t, suite.TestCases[0].Skipped)
+
+	require.NotNil(t, suite.TestCases[1].Failure)
+	assert.Equal(t, "exited with code 3", suite.TestCases[1].Failure.Message)
+	assert.Equal(t, "first\nlast", suite.TestCases[1].Failure.Contents)
+
+	assert.NotNil(t, suite.TestCases[2].Skipped)
+}
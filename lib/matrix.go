@@ -0,0 +1,358 @@
+package lib
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Matrix describes how a job expands into one job per
+// combination of the values of its axes, e.g.:
+//
+//	Matrix:
+//	  go: [ '1.20', '1.21' ]
+//	  db: [ 'pg', 'mysql' ]
+//	  Exclude:
+//	    - { go: '1.20', db: 'mysql' }
+//	  Include:
+//	    - { go: '1.22', db: 'pg' }
+type Matrix struct {
+	// Axes lists the axes in the order they're declared,
+	// which is the order they appear in the ids of the
+	// expanded jobs.
+	Axes []*MatrixAxis
+
+	// Include lists extra combinations to expand into.
+	Include []map[string]string
+
+	// Exclude lists combinations of the axes that should
+	// not be expanded into. An entry matches the
+	// combinations that have all of its values.
+	Exclude []map[string]string
+}
+
+// MatrixAxis is a named dimension of a Matrix.
+type MatrixAxis struct {
+	Name   string
+	Values []string
+}
+
+// UnmarshalYAML parses a matrix keeping the order in
+// which the axes are declared.
+func (m *Matrix) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	var fields yaml.MapSlice
+
+	err = unmarshal(&fields)
+	if err != nil {
+		return
+	}
+
+	for _, field := range fields {
+		name := fmt.Sprint(field.Key)
+
+		switch name {
+		case "Include", "Exclude":
+			var combinations []map[string]string
+
+			combinations, err = matrixCombinationsFromYaml(field.Value)
+			if err != nil {
+				err = errors.Wrapf(err, "invalid matrix %s", name)
+				return
+			}
+
+			if name == "Include" {
+				m.Include = combinations
+			} else {
+				m.Exclude = combinations
+			}
+		default:
+			values, ok := field.Value.([]interface{})
+			if !ok {
+				err = errors.Errorf(
+					"matrix axis %s must be a list of values", name)
+				return
+			}
+
+			axis := &MatrixAxis{Name: name}
+			for _, value := range values {
+				axis.Values = append(axis.Values, fmt.Sprint(value))
+			}
+
+			m.Axes = append(m.Axes, axis)
+		}
+	}
+
+	return
+}
+
+func matrixCombinationsFromYaml(value interface{}) (combinations []map[string]string, err error) {
+	entries, ok := value.([]interface{})
+	if !ok {
+		err = errors.Errorf("must be a list of combinations")
+		return
+	}
+
+	for _, entry := range entries {
+		fields, ok := entry.(yaml.MapSlice)
+		if !ok {
+			err = errors.Errorf("combinations must map axes to values")
+			return
+		}
+
+		combination := map[string]string{}
+		for _, field := range fields {
+			combination[fmt.Sprint(field.Key)] = fmt.Sprint(field.Value)
+		}
+
+		combinations = append(combinations, combination)
+	}
+
+	return
+}
+
+// Combinations lists every combination of the values of
+// the axes that isn't excluded, followed by the included
+// ones that aren't already part of the list.
+func (m *Matrix) Combinations() (combinations []map[string]string) {
+	product := []map[string]string{{}}
+
+	for _, axis := range m.Axes {
+		var next []map[string]string
+
+		for _, partial := range product {
+			for _, value := range axis.Values {
+				combination := map[string]string{axis.Name: value}
+				for k, v := range partial {
+					combination[k] = v
+				}
+
+				next = append(next, combination)
+			}
+		}
+
+		product = next
+	}
+
+	if len(m.Axes) == 0 {
+		product = nil
+	}
+
+	for _, combination := range product {
+		excluded := false
+		for _, exclude := range m.Exclude {
+			if matchesCombination(combination, exclude) {
+				excluded = true
+				break
+			}
+		}
+
+		if !excluded {
+			combinations = append(combinations, combination)
+		}
+	}
+
+	for _, include := range m.Include {
+		present := false
+		for _, combination := range combinations {
+			if len(combination) == len(include) && matchesCombination(combination, include) {
+				present = true
+				break
+			}
+		}
+
+		if !present {
+			combinations = append(combinations, include)
+		}
+	}
+
+	return
+}
+
+// matchesCombination indicates whether `combination` has
+// every value from `selector`.
+func matchesCombination(combination, selector map[string]string) bool {
+	for k, v := range selector {
+		value, present := combination[k]
+		if !present || value != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// id generates the id of the job expanded from the job `id`
+// for a combination, listing the values of the axes in the
+// order they're declared followed by any other value (from
+// included combinations) in alphabetical order.
+func (m *Matrix) id(id string, combination map[string]string) string {
+	var (
+		pairs []string
+		seen  = map[string]bool{}
+		extra []string
+	)
+
+	for _, axis := range m.Axes {
+		value, present := combination[axis.Name]
+		if !present {
+			continue
+		}
+
+		pairs = append(pairs, axis.Name+"="+value)
+		seen[axis.Name] = true
+	}
+
+	for k := range combination {
+		if !seen[k] {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+
+	for _, k := range extra {
+		pairs = append(pairs, k+"="+combination[k])
+	}
+
+	return id + "[" + strings.Join(pairs, ",") + "]"
+}
+
+// ExpandMatrices replaces each job that has a Matrix by one
+// job per combination of its values, making the values
+// available to its templates as `.Matrix`.
+// References to jobs in `DependsOn` are then resolved so
+// that the id of a job with a matrix refers to every job it
+// expanded into and `id[axis=value,...]` to the ones with
+// those values. References can make use of the values of the
+// matrix of the job, e.g. `build[os={{ .Matrix.os }}]`.
+func ExpandMatrices(jobs []*Job) (expanded []*Job, err error) {
+	if jobs == nil {
+		return
+	}
+
+	expanded = make([]*Job, 0, len(jobs))
+	for _, job := range jobs {
+		if job.Matrix == nil {
+			expanded = append(expanded, job)
+			continue
+		}
+
+		combinations := job.Matrix.Combinations()
+		if len(combinations) == 0 {
+			err = errors.Errorf(
+				"matrix of job %s has no combinations", job.Id)
+			return
+		}
+
+		for _, combination := range combinations {
+			cell := *job
+			cell.Id = job.Matrix.id(job.Id, combination)
+			cell.Matrix = nil
+			cell.MatrixValues = combination
+			cell.matrixOf = job.Id
+
+			expanded = append(expanded, &cell)
+		}
+	}
+
+	for _, job := range expanded {
+		var refs []string
+
+		for _, dep := range job.DependsOn {
+			var ref string
+
+			ref, err = TemplateField(dep, &RenderState{
+				Matrix: job.MatrixValues,
+			})
+			if err != nil {
+				err = errors.Wrapf(err,
+					"failed to render dependency of job %s", job.Id)
+				return
+			}
+
+			refs = append(refs, ref)
+		}
+
+		job.DependsOn = ResolveJobRefs(expanded, refs)
+	}
+
+	return
+}
+
+// ResolveJobRefs maps references to jobs to their ids. A
+// reference can be the id of a job, the id of a job with a
+// matrix (referring to every job it expanded into) or
+// `id[axis=value,...]` (referring to the jobs expanded from
+// `id` with those values). References that don't match any
+// job are kept as is.
+func ResolveJobRefs(jobs []*Job, refs []string) (ids []string) {
+	var (
+		seen = map[string]bool{}
+		byId = map[string]bool{}
+	)
+
+	for _, job := range jobs {
+		byId[job.Id] = true
+	}
+
+	exists := func(id string) bool {
+		return byId[id]
+	}
+
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, ref := range refs {
+		if exists(ref) {
+			add(ref)
+			continue
+		}
+
+		matched := false
+		base, selector := parseJobRef(ref)
+
+		for _, job := range jobs {
+			if job.matrixOf != "" && job.matrixOf == base &&
+				matchesCombination(job.MatrixValues, selector) {
+				add(job.Id)
+				matched = true
+			}
+		}
+
+		if !matched {
+			add(ref)
+		}
+	}
+
+	return
+}
+
+// parseJobRef splits a reference like `id[axis=value,...]`
+// into the id and the values.
+func parseJobRef(ref string) (id string, selector map[string]string) {
+	id = ref
+	selector = map[string]string{}
+
+	start := strings.Index(ref, "[")
+	if start == -1 || !strings.HasSuffix(ref, "]") {
+		return
+	}
+
+	id = ref[:start]
+	for _, pair := range strings.Split(ref[start+1:len(ref)-1], ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		selector[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return
+}
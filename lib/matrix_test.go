@@ -0,0 +1,245 @@
+package lib
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestMatrixUnmarshalYAML(t *testing.T) {
+	var job Job
+
+	err := yaml.Unmarshal([]byte(`
+Id: test
+Matrix:
+  go: [ '1.21', 1.22 ]
+  db: [ pg, mysql ]
+  Exclude:
+    - { go: '1.21', db: mysql }
+  Include:
+    - { go: 1.23, db: sqlite, race: true }
+`), &job)
+	require.NoError(t, err)
+
+	require.NotNil(t, job.Matrix)
+	assert.Equal(t, []*MatrixAxis{
+		{Name: "go", Values: []string{"1.21", "1.22"}},
+		{Name: "db", Values: []string{"pg", "mysql"}},
+	}, job.Matrix.Axes)
+	assert.Equal(t, []map[string]string{
+		{"go": "1.21", "db": "mysql"},
+	}, job.Matrix.Exclude)
+	assert.Equal(t, []map[string]string{
+		{"go": "1.23", "db": "sqlite", "race": "true"},
+	}, job.Matrix.Include)
+
+	err = yaml.Unmarshal([]byte(`
+Id: test
+Matrix:
+  go: '1.21'
+`), &job)
+	assert.Error(t, err)
+}
+
+func TestMatrixCombinations(t *testing.T) {
+	var testCases = []struct {
+		desc     string
+		matrix   *Matrix
+		expected []map[string]string
+	}{
+		{
+			desc:     "no axes",
+			matrix:   &Matrix{},
+			expected: nil,
+		},
+		{
+			desc: "cartesian product in declaration order",
+			matrix: &Matrix{
+				Axes: []*MatrixAxis{
+					{Name: "go", Values: []string{"1.21", "1.22"}},
+					{Name: "db", Values: []string{"pg", "mysql"}},
+				},
+			},
+			expected: []map[string]string{
+				{"go": "1.21", "db": "pg"},
+				{"go": "1.21", "db": "mysql"},
+				{"go": "1.22", "db": "pg"},
+				{"go": "1.22", "db": "mysql"},
+			},
+		},
+		{
+			desc: "exclude matches partial combinations",
+			matrix: &Matrix{
+				Axes: []*MatrixAxis{
+					{Name: "go", Values: []string{"1.21", "1.22"}},
+					{Name: "db", Values: []string{"pg", "mysql"}},
+				},
+				Exclude: []map[string]string{{"db": "mysql"}},
+			},
+			expected: []map[string]string{
+				{"go": "1.21", "db": "pg"},
+				{"go": "1.22", "db": "pg"},
+			},
+		},
+		{
+			desc: "include adds missing combinations only",
+			matrix: &Matrix{
+				Axes: []*MatrixAxis{
+					{Name: "go", Values: []string{"1.21"}},
+				},
+				Include: []map[string]string{
+					{"go": "1.21"},
+					{"go": "1.22", "race": "true"},
+				},
+			},
+			expected: []map[string]string{
+				{"go": "1.21"},
+				{"go": "1.22", "race": "true"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.matrix.Combinations())
+		})
+	}
+}
+
+func TestExpandMatrices(t *testing.T) {
+	var testCases = []struct {
+		desc        string
+		jobs        []*Job
+		expected    map[string][]string
+		shouldError bool
+	}{
+		{
+			desc: "no matrix",
+			jobs: []*Job{
+				{Id: "a"},
+				{Id: "b", DependsOn: []string{"a"}},
+			},
+			expected: map[string][]string{
+				"a": nil,
+				"b": {"a"},
+			},
+		},
+		{
+			desc: "empty matrix",
+			jobs: []*Job{
+				{Id: "a", Matrix: &Matrix{}},
+			},
+			shouldError: true,
+		},
+		{
+			desc: "dependencies on the whole set a cell and a partial selection",
+			jobs: []*Job{
+				{Id: "test", Matrix: &Matrix{
+					Axes: []*MatrixAxis{
+						{Name: "go", Values: []string{"1.21", "1.22"}},
+						{Name: "db", Values: []string{"pg", "mysql"}},
+					},
+					Include: []map[string]string{{"go": "1.23", "db": "pg", "race": "true"}},
+				}},
+				{Id: "all", DependsOn: []string{"test"}},
+				{Id: "cell", DependsOn: []string{"test[db=pg,go=1.22]"}},
+				{Id: "partial", DependsOn: []string{"test[db=mysql]", "test[go=1.21,db=mysql]"}},
+				{Id: "unknown", DependsOn: []string{"test[db=oracle]"}},
+			},
+			expected: map[string][]string{
+				"test[go=1.21,db=pg]":           nil,
+				"test[go=1.21,db=mysql]":        nil,
+				"test[go=1.22,db=pg]":           nil,
+				"test[go=1.22,db=mysql]":        nil,
+				"test[go=1.23,db=pg,race=true]": nil,
+				"all": {
+					"test[go=1.21,db=pg]",
+					"test[go=1.21,db=mysql]",
+					"test[go=1.22,db=pg]",
+					"test[go=1.22,db=mysql]",
+					"test[go=1.23,db=pg,race=true]",
+				},
+				"cell":    {"test[go=1.22,db=pg]"},
+				"partial": {"test[go=1.21,db=mysql]", "test[go=1.22,db=mysql]"},
+				"unknown": {"test[db=oracle]"},
+			},
+		},
+		{
+			desc: "cell-wise dependencies between matrices",
+			jobs: []*Job{
+				{Id: "build", Matrix: &Matrix{
+					Axes: []*MatrixAxis{{Name: "os", Values: []string{"linux", "darwin"}}},
+				}},
+				{Id: "test", DependsOn: []string{"build[os={{ .Matrix.os }}]"}, Matrix: &Matrix{
+					Axes: []*MatrixAxis{{Name: "os", Values: []string{"linux", "darwin"}}},
+				}},
+			},
+			expected: map[string][]string{
+				"build[os=linux]":  nil,
+				"build[os=darwin]": nil,
+				"test[os=linux]":   {"build[os=linux]"},
+				"test[os=darwin]":  {"build[os=darwin]"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			expanded, err := ExpandMatrices(tc.jobs)
+			if tc.shouldError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			actual := map[string][]string{}
+			for _, job := range expanded {
+				actual[job.Id] = job.DependsOn
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestExecuteMatrix(t *testing.T) {
+	cfg := &Config{
+		Runtime: Runtime{
+			LogsDirectory: t.TempDir(),
+			Targets:       []string{"test[db=pg]"},
+		},
+		Jobs: []*Job{
+			{
+				Id:            "test",
+				Run:           "echo go={{ .Matrix.go }} db={{ .Matrix.db }}",
+				CaptureOutput: true,
+				Matrix: &Matrix{
+					Axes: []*MatrixAxis{
+						{Name: "go", Values: []string{"1.21", "1.22"}},
+						{Name: "db", Values: []string{"pg", "mysql"}},
+					},
+				},
+			},
+		},
+	}
+
+	e, err := New(cfg)
+	require.NoError(t, err)
+	require.NoError(t, e.Execute(context.Background()))
+
+	outputs := map[string]string{}
+	for _, job := range cfg.Jobs {
+		if job.Status == ActivitySuccess {
+			outputs[job.Id] = job.Output
+		}
+	}
+
+	assert.Equal(t, map[string]string{
+		"test[go=1.21,db=pg]": "go=1.21 db=pg",
+		"test[go=1.22,db=pg]": "go=1.22 db=pg",
+	}, outputs)
+}
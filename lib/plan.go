@@ -0,0 +1,164 @@
+package lib
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform/dag"
+	"github.com/pkg/errors"
+)
+
+var (
+	// pendingOutputRe matches the placeholders used in place
+	// of the outputs of jobs that haven't run yet.
+	pendingOutputRe = regexp.MustCompile("\x00output:([^\x00]*)\x00")
+)
+
+// PlannedJob describes how a job would be executed
+// given what's known before the execution starts.
+type PlannedJob struct {
+	Job         *Job
+	Directory   string
+	LogFilepath string
+	Run         string
+	Command     []string
+	Env         map[string]string
+
+	// Pending maps the name of the fields that can't be
+	// fully resolved to the ids of the jobs whose output
+	// they depend on.
+	Pending map[string][]string
+}
+
+// SortedJobs lists the jobs of the graph in topological
+// order, breaking ties using the order in which they are
+// declared in the configuration.
+func (e *Executor) SortedJobs() (res []*Job) {
+	var (
+		inDegree = map[dag.Vertex]int{}
+		ready    = []*Job{}
+	)
+
+	for _, v := range e.graph.Vertices() {
+		inDegree[v] = e.graph.UpEdges(v).Len()
+	}
+
+	byIndex := func(jobs []*Job) {
+		sort.SliceStable(jobs, func(i, j int) bool {
+			return e.jobsIndex[jobs[i].Id] < e.jobsIndex[jobs[j].Id]
+		})
+	}
+
+	for v, degree := range inDegree {
+		if degree == 0 {
+			ready = append(ready, v.(*Job))
+		}
+	}
+	byIndex(ready)
+
+	for len(ready) > 0 {
+		job := ready[0]
+		ready = ready[1:]
+
+		if job.Id != "_root" {
+			res = append(res, job)
+		}
+
+		for _, v := range e.graph.DownEdges(job).List() {
+			inDegree[v]--
+			if inDegree[v] == 0 {
+				ready = append(ready, v.(*Job))
+			}
+		}
+		byIndex(ready)
+	}
+
+	return
+}
+
+// Plan resolves the fields of every job that would be
+// executed, in topological order, without running any
+// of them. References to the output of other jobs are
+// kept as placeholders and reported as pending.
+func (e *Executor) Plan() (plan []*PlannedJob, err error) {
+	var (
+		renderState = &RenderState{
+			Jobs: map[string]*Job{},
+		}
+	)
+
+	for id, job := range e.jobsMap {
+		placeholder := *job
+		placeholder.Output = "\x00output:" + id + "\x00"
+		renderState.Jobs[id] = &placeholder
+	}
+
+	for _, job := range e.SortedJobs() {
+		renderState.Matrix = job.MatrixValues
+
+		planned := &PlannedJob{
+			Job:     job,
+			Pending: map[string][]string{},
+		}
+
+		planned.Directory, err = e.ResolveJobDirectory(job, renderState)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to plan job %s", job.Id)
+			return
+		}
+
+		planned.LogFilepath, err = e.ResolveJobLogFilepath(job, renderState)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to plan job %s", job.Id)
+			return
+		}
+
+		planned.Run, err = e.ResolveJobRun(job, renderState)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to plan job %s", job.Id)
+			return
+		}
+
+		planned.Command, err = e.ResolveJobCommand(job, renderState)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to plan job %s", job.Id)
+			return
+		}
+
+		planned.Env, err = e.ResolveJobEnv(job, renderState)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to plan job %s", job.Id)
+			return
+		}
+
+		planned.Directory = planned.markPending("Directory", planned.Directory)
+		planned.LogFilepath = planned.markPending("LogFilepath", planned.LogFilepath)
+		planned.Run = planned.markPending("Run", planned.Run)
+		commandField := "Command"
+		if job.Func != nil {
+			commandField = "Func"
+		}
+		for idx, arg := range planned.Command {
+			planned.Command[idx] = planned.markPending(commandField, arg)
+		}
+		for k, v := range planned.Env {
+			planned.Env[k] = planned.markPending("Env."+k, v)
+		}
+
+		plan = append(plan, planned)
+	}
+
+	return
+}
+
+// markPending replaces the output placeholders from a
+// resolved field with a readable reference, recording
+// the jobs that the field depends on.
+func (p *PlannedJob) markPending(field, value string) string {
+	return pendingOutputRe.ReplaceAllStringFunc(value, func(m string) string {
+		id := pendingOutputRe.FindStringSubmatch(m)[1]
+		p.Pending[field] = append(p.Pending[field], id)
+
+		return "<output of " + id + ">"
+	})
+}
@@ -0,0 +1,43 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan(t *testing.T) {
+	cfg := &Config{
+		Runtime: Runtime{LogsDirectory: "/tmp"},
+		Jobs: []*Job{
+			{Id: "lint", Run: "make lint"},
+			{
+				Id:        "build",
+				Run:       "make VERSION={{ .Jobs.version.Output }}",
+				DependsOn: []string{"version"},
+			},
+			{Id: "version", Run: "git describe", CaptureOutput: true},
+		},
+	}
+
+	e, err := New(cfg)
+	require.NoError(t, err)
+
+	plan, err := e.Plan()
+	require.NoError(t, err)
+	require.Len(t, plan, 3)
+
+	assert.Equal(t, "lint", plan[0].Job.Id)
+	assert.Equal(t, "version", plan[1].Job.Id)
+	assert.Equal(t, "build", plan[2].Job.Id)
+
+	assert.Empty(t, plan[0].Pending)
+	assert.Equal(t, e.RunDirectory()+"/lint", plan[0].LogFilepath)
+
+	assert.Equal(t, "make VERSION=<output of version>", plan[2].Run)
+	assert.Equal(t, map[string][]string{"Run": {"version"}}, plan[2].Pending)
+
+	// planning must not touch the jobs themselves
+	assert.Equal(t, "make VERSION={{ .Jobs.version.Output }}", cfg.Jobs[1].Run)
+}
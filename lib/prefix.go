@@ -0,0 +1,122 @@
+package lib
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+var (
+	// prefixColors are the colors assigned to the prefixes
+	// of the jobs, cycling through them in the order the
+	// jobs are declared.
+	prefixColors = []*color.Color{
+		color.New(color.FgCyan),
+		color.New(color.FgYellow),
+		color.New(color.FgGreen),
+		color.New(color.FgMagenta),
+		color.New(color.FgBlue),
+		color.New(color.FgHiCyan),
+		color.New(color.FgHiYellow),
+		color.New(color.FgHiGreen),
+		color.New(color.FgHiMagenta),
+		color.New(color.FgHiBlue),
+	}
+)
+
+// PrefixWriter is a line-buffered writer that prefixes each
+// line with a fixed string and optionally the time at which
+// the line got completed.
+// Writers sharing the same lock never interleave their lines
+// as each complete line is written at once while holding it.
+type PrefixWriter struct {
+	writer     io.Writer
+	lock       sync.Locker
+	prefix     string
+	timestamps bool
+	buf        []byte
+}
+
+// NewPrefixWriter instantiates a PrefixWriter that writes
+// prefixed lines to `w` while holding `lock`.
+func NewPrefixWriter(w io.Writer, lock sync.Locker, prefix string, timestamps bool) *PrefixWriter {
+	return &PrefixWriter{
+		writer:     w,
+		lock:       lock,
+		prefix:     prefix,
+		timestamps: timestamps,
+	}
+}
+
+// Write buffers `p`, writing out every line that
+// got completed.
+func (w *PrefixWriter) Write(p []byte) (n int, err error) {
+	n = len(p)
+	w.buf = append(w.buf, p...)
+
+	idx := bytes.LastIndexByte(w.buf, '\n')
+	if idx == -1 {
+		return
+	}
+
+	err = w.writeLines(w.buf[:idx+1])
+	w.buf = w.buf[idx+1:]
+	return
+}
+
+// Flush writes out whatever has been buffered, terminating
+// it with a newline if it's an incomplete line.
+func (w *PrefixWriter) Flush() (err error) {
+	if len(w.buf) == 0 {
+		return
+	}
+
+	err = w.writeLines(append(w.buf, '\n'))
+	w.buf = nil
+	return
+}
+
+// writeLines prefixes each of the newline-terminated
+// lines in `lines` and writes them all at once.
+func (w *PrefixWriter) writeLines(lines []byte) (err error) {
+	var (
+		out    bytes.Buffer
+		prefix = w.prefix
+	)
+
+	if w.timestamps {
+		prefix = time.Now().Format("15:04:05.000") + " " + prefix
+	}
+
+	for len(lines) > 0 {
+		idx := bytes.IndexByte(lines, '\n')
+
+		out.WriteString(prefix)
+		out.Write(lines[:idx+1])
+		lines = lines[idx+1:]
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	_, err = w.writer.Write(out.Bytes())
+	return
+}
+
+// jobPrefix builds the prefix of the lines of output of a
+// job, padded so that the output of every job is aligned
+// and colored according to its position in the configuration.
+func (e *Executor) jobPrefix(j *Job) string {
+	return prefixColors[e.jobsIndex[j.Id]%len(prefixColors)].
+		Sprintf("%-*s |", e.prefixWidth, j.Id) + " "
+}
+
+// newPrefixWriter creates a PrefixWriter for the output
+// of a job that goes to `w`.
+func (e *Executor) newPrefixWriter(w io.Writer, j *Job) *PrefixWriter {
+	return NewPrefixWriter(w, e.outputLock,
+		e.jobPrefix(j), e.config.Runtime.Timestamps)
+}
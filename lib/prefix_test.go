@@ -0,0 +1,101 @@
+package lib
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var testCases = []struct {
+		desc     string
+		writes   []string
+		expected string
+	}{
+		{
+			desc:     "nothing written",
+			writes:   []string{},
+			expected: "",
+		},
+		{
+			desc:     "complete lines",
+			writes:   []string{"a\nb\n"},
+			expected: "job | a\njob | b\n",
+		},
+		{
+			desc:     "lines split across writes",
+			writes:   []string{"fir", "st\nsec", "ond\n"},
+			expected: "job | first\njob | second\n",
+		},
+		{
+			desc:     "incomplete line gets terminated on flush",
+			writes:   []string{"a\npartial"},
+			expected: "job | a\njob | partial\n",
+		},
+		{
+			desc:     "empty lines",
+			writes:   []string{"\n\n"},
+			expected: "job | \njob | \n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			w := NewPrefixWriter(&buf, &sync.Mutex{}, "job | ", false)
+			for _, write := range tc.writes {
+				n, err := w.Write([]byte(write))
+				require.NoError(t, err)
+				assert.Equal(t, len(write), n)
+			}
+
+			require.NoError(t, w.Flush())
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestPrefixWriterTimestamps(t *testing.T) {
+	var buf bytes.Buffer
+
+	w := NewPrefixWriter(&buf, &sync.Mutex{}, "job | ", true)
+	_, err := w.Write([]byte("a\n"))
+	require.NoError(t, err)
+
+	assert.Regexp(t,
+		regexp.MustCompile(`^\d{2}:\d{2}:\d{2}\.\d{3} job \| a\n$`),
+		buf.String())
+}
+
+func TestPrefixWriterConcurrentLines(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		lock sync.Mutex
+		wg   sync.WaitGroup
+	)
+
+	for _, prefix := range []string{"a | ", "b | "} {
+		w := NewPrefixWriter(&buf, &lock, prefix, false)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < 100; i++ {
+				w.Write([]byte("some "))
+				w.Write([]byte("line\n"))
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for _, line := range bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n")) {
+		assert.Regexp(t, `^[ab] \| some line$`, string(line))
+	}
+}
@@ -0,0 +1,132 @@
+package lib
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Report is a machine-readable summary of an execution.
+type Report struct {
+	Id        string    `json:"id"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+
+	// Duration is the wall-clock duration of the
+	// execution in seconds.
+	Duration float64 `json:"duration"`
+
+	// Succeeded indicates whether every job of the
+	// execution graph succeeded.
+	Succeeded bool `json:"succeeded"`
+
+	LogsDirectory string `json:"logsDirectory"`
+
+	// CriticalPath lists the ids of the chain of dependent
+	// jobs that took the longest to execute.
+	CriticalPath []string `json:"criticalPath"`
+
+	// CriticalPathDuration is the sum of the durations of
+	// the jobs on the critical path in seconds.
+	CriticalPathDuration float64 `json:"criticalPathDuration"`
+
+	Edges []*ReportEdge `json:"edges"`
+	Jobs  []*ReportJob  `json:"jobs"`
+}
+
+// ReportEdge is a dependency between two jobs: `To`
+// only runs after `From` succeeded.
+type ReportEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ReportJob is the final state of a job.
+type ReportJob struct {
+	*JobState
+
+	// Duration is the duration of the execution of
+	// the job in seconds.
+	Duration float64 `json:"duration"`
+
+	// Slack is how much longer, in seconds, the job could
+	// have taken without delaying the end of the execution.
+	// Jobs that didn't run have none.
+	Slack *float64 `json:"slack,omitempty"`
+}
+
+// GetReport summarizes the execution, considering only the
+// jobs that are part of the execution graph.
+func (e *Executor) GetReport() (report *Report) {
+	var (
+		state        = e.GetState()
+		criticalPath = e.GetCriticalPath()
+	)
+
+	report = &Report{
+		Id:            state.Id,
+		StartTime:     state.StartTime,
+		EndTime:       state.EndTime,
+		Duration:      state.EndTime.Sub(state.StartTime).Seconds(),
+		Succeeded:     true,
+		LogsDirectory: e.RunDirectory(),
+
+		CriticalPath:         criticalPath.Jobs,
+		CriticalPathDuration: criticalPath.Duration.Seconds(),
+
+		Edges: []*ReportEdge{},
+		Jobs:  []*ReportJob{},
+	}
+
+	for idx, job := range e.config.Jobs {
+		if !e.graph.HasVertex(job) {
+			continue
+		}
+
+		jobState := state.Jobs[idx]
+		if !jobState.Succeeded() {
+			report.Succeeded = false
+		}
+
+		reportJob := &ReportJob{
+			JobState: jobState,
+		}
+
+		if slack, ran := criticalPath.Slack[job.Id]; ran {
+			seconds := slack.Seconds()
+			reportJob.Slack = &seconds
+		}
+
+		if job.StartTime != nil && job.EndTime != nil {
+			reportJob.Duration = job.EndTime.Sub(*job.StartTime).Seconds()
+		}
+
+		report.Jobs = append(report.Jobs, reportJob)
+
+		for _, dep := range job.DependsOn {
+			report.Edges = append(report.Edges, &ReportEdge{
+				From: dep,
+				To:   job.Id,
+			})
+		}
+	}
+
+	return
+}
+
+// WriteJSONReport writes the summary of the execution
+// as a JSON document.
+func (e *Executor) WriteJSONReport(w io.Writer) (err error) {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(e.GetReport())
+	if err != nil {
+		err = errors.Wrapf(err, "failed to write json report")
+		return
+	}
+
+	return
+}
@@ -0,0 +1,60 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSONReport(t *testing.T) {
+	var (
+		buf    bytes.Buffer
+		report Report
+	)
+
+	e, err := New(&Config{
+		Runtime: Runtime{LogsDirectory: t.TempDir()},
+		Jobs: []*Job{
+			{Id: "version", Run: "echo 1.0", CaptureOutput: true},
+			{Id: "fail", Run: "exit 2", DependsOn: []string{"version"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Error(t, e.Execute(context.Background()))
+
+	require.NoError(t, e.WriteJSONReport(&buf))
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+
+	assert.Equal(t, e.RunId(), report.Id)
+	assert.False(t, report.Succeeded)
+	assert.Equal(t, []*ReportEdge{{From: "version", To: "fail"}}, report.Edges)
+
+	require.Len(t, report.Jobs, 2)
+	assert.Equal(t, "SUCCESS", report.Jobs[0].Status)
+	assert.Equal(t, "1.0", report.Jobs[0].Output)
+	assert.Equal(t, "ERRORED", report.Jobs[1].Status)
+	assert.Equal(t, 2, report.Jobs[1].ExitCode)
+	assert.True(t, report.Jobs[1].Duration > 0)
+}
+
+func TestNewJobOutputWithJSONReportOnStdout(t *testing.T) {
+	for _, reportJson := range []string{"", "report.json", "-"} {
+		e, err := New(&Config{
+			Runtime: Runtime{LogsDirectory: t.TempDir(), ReportJson: reportJson},
+			Jobs:    []*Job{{Id: "job", Run: "true"}},
+		})
+		require.NoError(t, err)
+
+		if reportJson == "-" {
+			assert.Equal(t, os.Stderr, e.output, reportJson)
+			continue
+		}
+
+		assert.Equal(t, os.Stdout, e.output, reportJson)
+	}
+}
@@ -0,0 +1,103 @@
+package lib
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// LocalRunnerName is the name of the runner used by
+	// jobs that don't specify one.
+	LocalRunnerName = "local"
+)
+
+// Runner knows how to execute the command of a job.
+// Implementations can be registered under a name via
+// `Config.Runners` and selected by jobs via `Job.Runner`.
+type Runner interface {
+	// Prepare sets up everything needed to carry out
+	// `execution` without starting it.
+	// The output of the execution must be streamed to
+	// `execution.Stdout` and `execution.Stderr` as it's
+	// produced.
+	Prepare(execution *Execution) (p Process, err error)
+}
+
+// Process is an execution prepared by a Runner.
+type Process interface {
+	// Start starts the execution without waiting for
+	// it to complete.
+	Start() (err error)
+
+	// Wait blocks until the execution completes,
+	// retrieving its exit code. A non-nil error
+	// indicates that the execution failed.
+	Wait() (exitCode int, err error)
+
+	// Kill forcibly terminates the execution, making
+	// Wait return.
+	Kill() (err error)
+}
+
+// LocalRunner executes commands as local processes.
+type LocalRunner struct{}
+
+type localProcess struct {
+	cmd *exec.Cmd
+}
+
+// Prepare creates the command that executes the argv of
+// the execution in a process group of its own so that
+// killing it kills not only the process but also every
+// process it spawned.
+func (r *LocalRunner) Prepare(execution *Execution) (p Process, err error) {
+	if len(execution.Argv) == 0 {
+		err = errors.Errorf("Argv must have at least one element")
+		return
+	}
+
+	allEnv := os.Environ()
+	for k, v := range execution.Env {
+		allEnv = append(allEnv, k+"="+v)
+	}
+
+	cmd := exec.Command(execution.Argv[0], execution.Argv[1:]...)
+	cmd.Stdout = execution.Stdout
+	cmd.Stderr = execution.Stderr
+	cmd.Dir = execution.Directory
+	cmd.Env = allEnv
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+
+	p = &localProcess{
+		cmd: cmd,
+	}
+	return
+}
+
+func (p *localProcess) Start() (err error) {
+	return p.cmd.Start()
+}
+
+func (p *localProcess) Wait() (exitCode int, err error) {
+	err = p.cmd.Wait()
+	if err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			exitCode = exitError.Sys().(syscall.WaitStatus).ExitStatus()
+		} else {
+			exitCode = defaultFailedExitCode
+		}
+		return
+	}
+
+	exitCode = p.cmd.ProcessState.Sys().(syscall.WaitStatus).ExitStatus()
+	return
+}
+
+func (p *localProcess) Kill() (err error) {
+	return syscall.Kill(-p.cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,133 @@
+package lib
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner records the executions it's asked to carry out,
+// writing their argv to stdout and exiting with the code
+// given by the last argument (if it's a number).
+// Executions whose argv is `block` only finish when killed.
+type fakeRunner struct {
+	executions []*Execution
+	sync.Mutex
+}
+
+type fakeProcess struct {
+	execution *Execution
+	killed    chan struct{}
+}
+
+func (r *fakeRunner) Prepare(execution *Execution) (p Process, err error) {
+	r.Lock()
+	r.executions = append(r.executions, execution)
+	r.Unlock()
+
+	p = &fakeProcess{
+		execution: execution,
+		killed:    make(chan struct{}),
+	}
+	return
+}
+
+func (p *fakeProcess) Start() (err error) {
+	fmt.Fprintln(p.execution.Stdout, strings.Join(p.execution.Argv, " "))
+	return
+}
+
+func (p *fakeProcess) Wait() (exitCode int, err error) {
+	argv := p.execution.Argv
+
+	if argv[0] == "block" {
+		<-p.killed
+		exitCode = -1
+		err = fmt.Errorf("killed")
+		return
+	}
+
+	fmt.Sscanf(argv[len(argv)-1], "%d", &exitCode)
+	if exitCode != 0 {
+		err = fmt.Errorf("exit code %d", exitCode)
+	}
+
+	return
+}
+
+func (p *fakeProcess) Kill() (err error) {
+	close(p.killed)
+	return
+}
+
+func TestExecuteCustomRunner(t *testing.T) {
+	var (
+		runner = &fakeRunner{}
+		local  = &Job{Id: "local", Run: "echo local", CaptureOutput: true}
+		fake   = &Job{
+			Id:            "fake",
+			Runner:        "fake",
+			Command:       []string{"deploy", "{{ .Jobs.local.Output }}", "0"},
+			CaptureOutput: true,
+			DependsOn:     []string{"local"},
+		}
+		failing = &Job{Id: "failing", Runner: "fake", Command: []string{"test", "3"}}
+	)
+
+	e, err := New(&Config{
+		Runtime: Runtime{LogsDirectory: t.TempDir(), KeepGoing: true},
+		Runners: map[string]Runner{"fake": runner},
+		Jobs:    []*Job{local, fake, failing},
+	})
+	require.NoError(t, err)
+	require.Error(t, e.Execute(context.Background()))
+
+	runner.Lock()
+	executions := runner.executions
+	runner.Unlock()
+	require.Len(t, executions, 2)
+
+	assert.Equal(t, ActivitySuccess, fake.Status)
+	assert.Equal(t, "deploy local 0", fake.Output)
+
+	assert.Equal(t, ActivityErrored, failing.Status)
+	assert.Equal(t, 3, failing.ExitCode)
+
+	logs, err := ioutil.ReadFile(failing.LogFilepath)
+	require.NoError(t, err)
+	assert.Equal(t, "test 3\n", string(logs))
+}
+
+func TestExecuteCustomRunnerTimeout(t *testing.T) {
+	job := &Job{
+		Id:      "blocked",
+		Runner:  "fake",
+		Command: []string{"block"},
+		Timeout: 100 * time.Millisecond,
+	}
+
+	e, err := New(&Config{
+		Runtime: Runtime{LogsDirectory: t.TempDir()},
+		Runners: map[string]Runner{"fake": &fakeRunner{}},
+		Jobs:    []*Job{job},
+	})
+	require.NoError(t, err)
+	require.Error(t, e.Execute(context.Background()))
+
+	assert.Equal(t, ActivityTimeout, job.Status)
+}
+
+func TestNewUnknownRunner(t *testing.T) {
+	_, err := New(&Config{
+		Runtime: Runtime{LogsDirectory: t.TempDir()},
+		Jobs:    []*Job{{Id: "a", Run: "true", Runner: "docker"}},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package lib
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// NewRunId generates the identifier of an execution made
+// of the time it started and a random name so that ids
+// sort chronologically.
+func NewRunId(t time.Time) string {
+	return t.Format("20060102-150405") + "-" + GetRandomName()
+}
+
+// ListRuns retrieves the state of every run recorded under
+// the logs directory, most recent first. Runs whose state
+// can't be loaded are left out, with the reasons reported
+// in `invalid`.
+func ListRuns(logsDirectory string) (runs []*RunState, invalid []error, err error) {
+	entries, err := ioutil.ReadDir(logsDirectory)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to list logs directory %s",
+			logsDirectory)
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		stateFile := path.Join(logsDirectory, entry.Name(), stateFilename)
+		if _, statErr := os.Stat(stateFile); statErr != nil {
+			continue
+		}
+
+		run, loadErr := LoadState(stateFile)
+		if loadErr != nil {
+			invalid = append(invalid, loadErr)
+			continue
+		}
+
+		runs = append(runs, run)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartTime.After(runs[j].StartTime)
+	})
+
+	return
+}
+
+// LoadRun retrieves the state of the run identified by `id`.
+func LoadRun(logsDirectory, id string) (run *RunState, err error) {
+	if id == "" || strings.ContainsAny(id, "/\\") {
+		err = errors.Errorf("invalid run id '%s'", id)
+		return
+	}
+
+	run, err = LoadState(path.Join(logsDirectory, id, stateFilename))
+	return
+}
+
+// Job retrieves the state of the job identified by `id`.
+func (r *RunState) Job(id string) (job *JobState, err error) {
+	for _, job = range r.Jobs {
+		if job.Id == id {
+			return
+		}
+	}
+
+	job = nil
+	err = errors.Errorf("job %s not found in run %s", id, r.Id)
+	return
+}
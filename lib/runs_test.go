@@ -0,0 +1,33 @@
+package lib
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListRunsSkipsInvalidState(t *testing.T) {
+	var dir = t.TempDir()
+
+	write := func(id, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, id), 0755))
+		require.NoError(t, ioutil.WriteFile(
+			filepath.Join(dir, id, stateFilename), []byte(content), 0644))
+	}
+
+	write("20240101-000000-valid", `{"id": "20240101-000000-valid"}`)
+	write("20240102-000000-truncated", `{"id": "20240102-0`)
+
+	runs, invalid, err := ListRuns(dir)
+	require.NoError(t, err)
+
+	require.Len(t, runs, 1)
+	assert.Equal(t, "20240101-000000-valid", runs[0].Id)
+
+	require.Len(t, invalid, 1)
+	assert.Contains(t, invalid[0].Error(), "20240102-000000-truncated")
+}
@@ -0,0 +1,216 @@
+package lib
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Scheduler limits the number of jobs that can run
+// at the same time as well as how many of them can
+// make use of each of the named resources.
+// Jobs that can't run right away are queued and get
+// their slots granted in order of priority (lower
+// first) as soon as everything they need is free.
+type Scheduler struct {
+	capacity  int
+	running   int
+	resources map[string]int
+	inUse     map[string]int
+	queue     []*ticket
+
+	sync.Mutex
+}
+
+// ticket represents a job waiting for a slot.
+type ticket struct {
+	priority int
+	uses     []string
+	granted  chan struct{}
+}
+
+// NewScheduler instantiates a Scheduler that allows
+// at most `capacity` jobs to run at once. A capacity
+// of zero means that there's no limit.
+// `resources` maps the name of each resource pool to
+// the number of jobs that can use it at once.
+func NewScheduler(capacity int, resources map[string]int) (s *Scheduler, err error) {
+	if capacity < 0 {
+		err = errors.Errorf("capacity can't be negative")
+		return
+	}
+
+	for name, size := range resources {
+		if size <= 0 {
+			err = errors.Errorf(
+				"resource %s must have a positive capacity",
+				name)
+			return
+		}
+	}
+
+	s = &Scheduler{
+		capacity:  capacity,
+		resources: resources,
+		inUse:     map[string]int{},
+	}
+
+	return
+}
+
+// Validate checks whether every resource listed in
+// `uses` is known by the scheduler and listed only once.
+func (s *Scheduler) Validate(uses []string) (err error) {
+	seen := map[string]bool{}
+
+	for _, name := range uses {
+		_, present := s.resources[name]
+		if !present {
+			err = errors.Errorf(
+				"resource %s does not exist", name)
+			return
+		}
+
+		if seen[name] {
+			err = errors.Errorf(
+				"resource %s listed more than once", name)
+			return
+		}
+
+		seen[name] = true
+	}
+
+	return
+}
+
+// Acquire blocks until a slot and every resource listed in
+// `uses` are available or the context is cancelled. If they
+// can't be granted immediately, `onQueued` is called once the
+// job has been queued.
+func (s *Scheduler) Acquire(ctx context.Context, priority int, uses []string, onQueued func()) (err error) {
+	t := &ticket{
+		priority: priority,
+		uses:     uses,
+		granted:  make(chan struct{}),
+	}
+
+	s.Lock()
+	s.queue = append(s.queue, t)
+	sort.SliceStable(s.queue, func(i, j int) bool {
+		return s.queue[i].priority < s.queue[j].priority
+	})
+	s.dispatch()
+	s.Unlock()
+
+	select {
+	case <-t.granted:
+		return
+	default:
+	}
+
+	if onQueued != nil {
+		onQueued()
+	}
+
+	select {
+	case <-t.granted:
+		return
+	case <-ctx.Done():
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	select {
+	case <-t.granted:
+		// granted while giving up: hand what has
+		// been taken over to the next in line.
+		s.free(t.uses)
+		s.dispatch()
+	default:
+		s.remove(t)
+	}
+
+	err = ctx.Err()
+	return
+}
+
+// Release gives back the slot and the resources acquired
+// with Acquire.
+func (s *Scheduler) Release(uses []string) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.free(uses)
+	s.dispatch()
+}
+
+// Stats retrieves the number of jobs currently running
+// and waiting for a slot.
+func (s *Scheduler) Stats() (running, queued int) {
+	s.Lock()
+	defer s.Unlock()
+
+	running = s.running
+	queued = len(s.queue)
+	return
+}
+
+// fits indicates whether a ticket could be granted right
+// now. Must be called with the lock held.
+func (s *Scheduler) fits(t *ticket) bool {
+	if s.capacity > 0 && s.running >= s.capacity {
+		return false
+	}
+
+	for _, name := range t.uses {
+		if s.inUse[name] >= s.resources[name] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// free gives back a slot and the resources listed.
+// Must be called with the lock held.
+func (s *Scheduler) free(uses []string) {
+	s.running--
+	for _, name := range uses {
+		s.inUse[name]--
+	}
+}
+
+// dispatch grants slots to the queued tickets that fit,
+// in order of priority. Must be called with the lock held.
+func (s *Scheduler) dispatch() {
+	pending := s.queue[:0]
+
+	for _, t := range s.queue {
+		if !s.fits(t) {
+			pending = append(pending, t)
+			continue
+		}
+
+		s.running++
+		for _, name := range t.uses {
+			s.inUse[name]++
+		}
+		close(t.granted)
+	}
+
+	s.queue = pending
+}
+
+// remove takes a ticket out of the queue. Must be called
+// with the lock held.
+func (s *Scheduler) remove(t *ticket) {
+	for i, queued := range s.queue {
+		if queued == t {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package lib
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerGrantsInPriorityOrder(t *testing.T) {
+	var (
+		granted = make(chan int, 3)
+		queued  = make(chan struct{}, 3)
+	)
+
+	s, err := NewScheduler(1, nil)
+	require.NoError(t, err)
+	require.NoError(t, s.Acquire(context.Background(), 0, nil, nil))
+
+	for _, priority := range []int{3, 1, 2} {
+		go func(p int) {
+			s.Acquire(context.Background(), p, nil, func() {
+				queued <- struct{}{}
+			})
+			granted <- p
+		}(priority)
+		<-queued
+	}
+
+	running, waiting := s.Stats()
+	assert.Equal(t, 1, running)
+	assert.Equal(t, 3, waiting)
+
+	for _, expected := range []int{1, 2, 3} {
+		s.Release(nil)
+		assert.Equal(t, expected, <-granted)
+	}
+}
+
+func TestSchedulerAcquireCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	s, err := NewScheduler(1, nil)
+	require.NoError(t, err)
+	require.NoError(t, s.Acquire(context.Background(), 0, nil, nil))
+
+	err = s.Acquire(ctx, 1, nil, nil)
+	require.Error(t, err)
+
+	running, waiting := s.Stats()
+	assert.Equal(t, 1, running)
+	assert.Equal(t, 0, waiting)
+}
+
+func TestSchedulerUnlimited(t *testing.T) {
+	s, err := NewScheduler(0, nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, s.Acquire(context.Background(), i, nil, nil))
+	}
+
+	running, _ := s.Stats()
+	assert.Equal(t, 100, running)
+}
+
+func TestSchedulerResources(t *testing.T) {
+	var (
+		db      = []string{"db"}
+		granted = make(chan int, 2)
+		queued  = make(chan struct{}, 2)
+	)
+
+	s, err := NewScheduler(0, map[string]int{"db": 1})
+	require.NoError(t, err)
+
+	require.Error(t, s.Validate([]string{"gpu"}))
+	require.Error(t, s.Validate([]string{"db", "db"}))
+	require.NoError(t, s.Validate(db))
+
+	require.NoError(t, s.Acquire(context.Background(), 0, db, nil))
+
+	go func() {
+		s.Acquire(context.Background(), 1, db, func() {
+			queued <- struct{}{}
+		})
+		granted <- 1
+	}()
+	<-queued
+
+	// jobs not using the resource aren't held back
+	require.NoError(t, s.Acquire(context.Background(), 2, nil, nil))
+
+	running, waiting := s.Stats()
+	assert.Equal(t, 2, running)
+	assert.Equal(t, 1, waiting)
+
+	s.Release(db)
+	assert.Equal(t, 1, <-granted)
+}
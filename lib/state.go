@@ -0,0 +1,241 @@
+package lib
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	stateFilename = "cr-state.json"
+)
+
+// JobState is the persisted result of a job execution.
+type JobState struct {
+	Id          string     `json:"id"`
+	Status      string     `json:"status"`
+	ExitCode    int        `json:"exitCode"`
+	StartTime   *time.Time `json:"startTime,omitempty"`
+	EndTime     *time.Time `json:"endTime,omitempty"`
+	LogFilepath string     `json:"logFilepath,omitempty"`
+	Output      string     `json:"output,omitempty"`
+}
+
+// RunState is the persisted result of a whole execution.
+type RunState struct {
+	Id         string      `json:"id"`
+	ConfigFile string      `json:"configFile,omitempty"`
+	StartTime  time.Time   `json:"startTime"`
+	EndTime    time.Time   `json:"endTime"`
+	Jobs       []*JobState `json:"jobs"`
+}
+
+// Succeeded indicates whether the job completed successfully,
+// either by being executed or by having its results reused.
+func (s *JobState) Succeeded() bool {
+	switch s.Status {
+	case ActivityMapping[ActivitySuccess],
+		ActivityMapping[ActivityCached],
+		ActivityMapping[ActivityUpToDate],
+		ActivityMapping[ActivityRestored]:
+		return true
+	}
+
+	return false
+}
+
+// StateFilepath retrieves the path to the file where the
+// state of the execution is persisted.
+func (e *Executor) StateFilepath() string {
+	return path.Join(e.RunDirectory(), stateFilename)
+}
+
+// GetState gathers the current state of every job.
+func (e *Executor) GetState() (state *RunState) {
+	state = e.newRunState()
+
+	for _, job := range e.config.Jobs {
+		state.Jobs = append(state.Jobs, newJobState(job))
+	}
+
+	return
+}
+
+func (e *Executor) newRunState() *RunState {
+	return &RunState{
+		Id:         e.runId,
+		ConfigFile: e.configFile,
+		StartTime:  e.startTime,
+		EndTime:    e.endTime,
+	}
+}
+
+func newJobState(job *Job) *JobState {
+	return &JobState{
+		Id:          job.Id,
+		Status:      ActivityMapping[job.Status],
+		ExitCode:    job.ExitCode,
+		StartTime:   job.StartTime,
+		EndTime:     job.EndTime,
+		LogFilepath: job.LogFilepath,
+		Output:      job.Output,
+	}
+}
+
+// SaveState persists the current state of every job to
+// the state file.
+func (e *Executor) SaveState() (err error) {
+	e.stateLock.Lock()
+	defer e.stateLock.Unlock()
+
+	err = e.writeState(e.GetState())
+	return
+}
+
+// saveJobState records the state of a job that just
+// finished and persists the state of the run so far, so
+// that it can be resumed even if it never gets to the end.
+// As other jobs may still be running, only the jobs that
+// finished are included.
+func (e *Executor) saveJobState(job *Job) {
+	e.stateLock.Lock()
+	defer e.stateLock.Unlock()
+
+	e.jobStates[job.Id] = newJobState(job)
+
+	state := e.newRunState()
+	for _, job := range e.config.Jobs {
+		jobState, present := e.jobStates[job.Id]
+		if !present {
+			jobState = &JobState{
+				Id:     job.Id,
+				Status: ActivityMapping[ActivityUnknown],
+			}
+		}
+
+		state.Jobs = append(state.Jobs, jobState)
+	}
+
+	// failures surface when saving the final state
+	_ = e.writeState(state)
+}
+
+// writeState replaces the state file with `state`. The
+// content goes to a temporary file that's then renamed over
+// the state file so that getting killed half way through
+// never leaves a truncated state behind.
+func (e *Executor) writeState(state *RunState) (err error) {
+	var tmp *os.File
+
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		err = errors.Wrapf(err, "failed to serialize run state")
+		return
+	}
+
+	tmp, err = ioutil.TempFile(e.RunDirectory(), "."+stateFilename+".*")
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to create temporary run state in %s",
+			e.RunDirectory())
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(content)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to write run state to %s",
+			tmp.Name())
+		return
+	}
+
+	err = os.Rename(tmp.Name(), e.StateFilepath())
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to write run state to %s",
+			e.StateFilepath())
+		return
+	}
+
+	return
+}
+
+// LoadState reads a state persisted with SaveState.
+func LoadState(file string) (state *RunState, err error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = errors.Wrapf(err,
+				"no previous run state found at %s", file)
+			return
+		}
+
+		err = errors.Wrapf(err,
+			"failed to read run state %s", file)
+		return
+	}
+
+	state = &RunState{}
+	err = json.Unmarshal(content, state)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to parse run state %s", file)
+		return
+	}
+
+	return
+}
+
+// restoreState loads the state of the previous execution
+// of the same configuration file, marking the jobs that
+// succeeded there as restored so that they don't get
+// executed again.
+func (e *Executor) restoreState() (err error) {
+	var state *RunState
+
+	runs, _, err := ListRuns(e.logsDirectory)
+	if err != nil {
+		return
+	}
+
+	for _, run := range runs {
+		if run.Id != e.runId && run.ConfigFile == e.configFile {
+			state = run
+			break
+		}
+	}
+
+	if state == nil {
+		err = errors.Errorf(
+			"no previous run of %s found in %s",
+			e.configFile, e.logsDirectory)
+		return
+	}
+
+	for _, jobState := range state.Jobs {
+		job, present := e.jobsMap[jobState.Id]
+		if !present || !jobState.Succeeded() {
+			continue
+		}
+
+		job.ExitCode = jobState.ExitCode
+		job.StartTime = jobState.StartTime
+		job.EndTime = jobState.EndTime
+		job.LogFilepath = jobState.LogFilepath
+		job.Output = jobState.Output
+		e.restored[job.Id] = true
+	}
+
+	return
+}
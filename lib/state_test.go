@@ -0,0 +1,127 @@
+package lib
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteResume(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		flag = filepath.Join(dir, "flag")
+	)
+
+	var e Executor
+
+	run := func(resume bool) (jobs []*Job, err error) {
+		jobs = []*Job{
+			{Id: "version", Run: "echo 1.0", CaptureOutput: true},
+			{Id: "flaky", Run: "test -f " + flag},
+			{
+				Id:        "release",
+				Run:       "echo {{ .Jobs.version.Output }}",
+				DependsOn: []string{"version", "flaky"},
+			},
+		}
+
+		e, err = New(&Config{
+			Runtime: Runtime{
+				File:          filepath.Join(dir, "cr.yml"),
+				LogsDirectory: dir,
+				Resume:        resume,
+			},
+			Jobs: jobs,
+		})
+		require.NoError(t, err)
+
+		err = e.Execute(context.Background())
+		return
+	}
+
+	_, err := run(true)
+	require.Error(t, err)
+
+	jobs, err := run(false)
+	require.Error(t, err)
+	assert.Equal(t, ActivitySuccess, jobs[0].Status)
+	assert.Equal(t, ActivityErrored, jobs[1].Status)
+
+	state, err := LoadRun(dir, e.RunId())
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "cr.yml"), state.ConfigFile)
+	require.Len(t, state.Jobs, 3)
+	assert.Equal(t, "1.0", state.Jobs[0].Output)
+	assert.True(t, state.Jobs[0].Succeeded())
+	assert.False(t, state.Jobs[1].Succeeded())
+
+	require.NoError(t, ioutil.WriteFile(flag, nil, 0644))
+
+	jobs, err = run(true)
+	require.NoError(t, err)
+	assert.Equal(t, ActivityRestored, jobs[0].Status)
+	assert.Equal(t, ActivitySuccess, jobs[1].Status)
+	assert.Equal(t, ActivitySuccess, jobs[2].Status)
+	assert.Equal(t, "echo 1.0", jobs[2].Run)
+
+	runs, invalid, err := ListRuns(dir)
+	require.NoError(t, err)
+	assert.Empty(t, invalid)
+	require.Len(t, runs, 2)
+	assert.Equal(t, e.RunId(), runs[0].Id)
+	assert.Equal(t, state.Id, runs[1].Id)
+}
+
+func TestExecuteResumeOtherConfig(t *testing.T) {
+	var dir = t.TempDir()
+
+	run := func(file string, resume bool) error {
+		e, err := New(&Config{
+			Runtime: Runtime{
+				File:          filepath.Join(dir, file),
+				LogsDirectory: dir,
+				Resume:        resume,
+			},
+			Jobs: []*Job{{Id: "fail", Run: "false"}},
+		})
+		require.NoError(t, err)
+
+		return e.Execute(context.Background())
+	}
+
+	require.Error(t, run("a.yml", false))
+
+	err := run("b.yml", true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no previous run of")
+}
+
+func TestExecuteSavesStateAfterEachJob(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		first  = &Job{Id: "first", Run: "true"}
+		second = &Job{Id: "second", DependsOn: []string{"first"}}
+	)
+
+	e, err := New(&Config{
+		Runtime: Runtime{LogsDirectory: dir},
+		Jobs:    []*Job{first, second},
+	})
+	require.NoError(t, err)
+
+	second.Run = "grep -q SUCCESS " + e.StateFilepath()
+	require.NoError(t, e.Execute(context.Background()))
+
+	entries, err := ioutil.ReadDir(e.RunDirectory())
+	require.NoError(t, err)
+
+	var files []string
+	for _, entry := range entries {
+		files = append(files, entry.Name())
+	}
+	assert.Equal(t, []string{"cr-state.json", "first", "second"}, files)
+}
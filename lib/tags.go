@@ -0,0 +1,244 @@
+package lib
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// TagExpression is a boolean expression over the tags
+// of a job, e.g. `unit && !slow` or `lint || (unit && fast)`.
+type TagExpression interface {
+	// Match evaluates the expression against the set of
+	// tags of a job.
+	Match(tags map[string]bool) bool
+}
+
+type tagLiteral string
+
+type tagNot struct {
+	expr TagExpression
+}
+
+type tagAnd struct {
+	left, right TagExpression
+}
+
+type tagOr struct {
+	left, right TagExpression
+}
+
+func (t tagLiteral) Match(tags map[string]bool) bool {
+	return tags[string(t)]
+}
+
+func (t tagNot) Match(tags map[string]bool) bool {
+	return !t.expr.Match(tags)
+}
+
+func (t tagAnd) Match(tags map[string]bool) bool {
+	return t.left.Match(tags) && t.right.Match(tags)
+}
+
+func (t tagOr) Match(tags map[string]bool) bool {
+	return t.left.Match(tags) || t.right.Match(tags)
+}
+
+// tagParser is a recursive descent parser for tag
+// expressions following the grammar:
+//
+//	or    = and { "||" and }
+//	and   = unary { "&&" unary }
+//	unary = "!" unary | "(" or ")" | tag
+type tagParser struct {
+	tokens []string
+	pos    int
+}
+
+// ParseTagExpression parses a boolean expression made of
+// tags, `!`, `&&`, `||` and parenthesis.
+func ParseTagExpression(expr string) (res TagExpression, err error) {
+	p := &tagParser{
+		tokens: tokenizeTagExpression(expr),
+	}
+
+	if len(p.tokens) == 0 {
+		err = errors.Errorf("empty tag expression")
+		return
+	}
+
+	res, err = p.parseOr()
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to parse tag expression '%s'", expr)
+		return
+	}
+
+	if p.pos != len(p.tokens) {
+		err = errors.Errorf(
+			"unexpected '%s' in tag expression '%s'",
+			p.tokens[p.pos], expr)
+		return
+	}
+
+	return
+}
+
+func tokenizeTagExpression(expr string) (tokens []string) {
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+
+	for i := 0; i < len(expr); i++ {
+		c := rune(expr[i])
+
+		switch {
+		case unicode.IsSpace(c):
+			flush()
+		case c == '!' || c == '(' || c == ')':
+			flush()
+			tokens = append(tokens, string(c))
+		case (c == '&' || c == '|') && i+1 < len(expr) && rune(expr[i+1]) == c:
+			flush()
+			tokens = append(tokens, expr[i:i+2])
+			i++
+		default:
+			current.WriteRune(c)
+		}
+	}
+
+	flush()
+	return
+}
+
+func (p *tagParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+
+	return p.tokens[p.pos]
+}
+
+func (p *tagParser) parseOr() (res TagExpression, err error) {
+	res, err = p.parseAnd()
+	if err != nil {
+		return
+	}
+
+	for p.peek() == "||" {
+		var right TagExpression
+
+		p.pos++
+		right, err = p.parseAnd()
+		if err != nil {
+			return
+		}
+
+		res = tagOr{res, right}
+	}
+
+	return
+}
+
+func (p *tagParser) parseAnd() (res TagExpression, err error) {
+	res, err = p.parseUnary()
+	if err != nil {
+		return
+	}
+
+	for p.peek() == "&&" {
+		var right TagExpression
+
+		p.pos++
+		right, err = p.parseUnary()
+		if err != nil {
+			return
+		}
+
+		res = tagAnd{res, right}
+	}
+
+	return
+}
+
+func (p *tagParser) parseUnary() (res TagExpression, err error) {
+	token := p.peek()
+
+	switch token {
+	case "":
+		err = errors.Errorf("unexpected end of expression")
+	case "!":
+		p.pos++
+		res, err = p.parseUnary()
+		if err != nil {
+			return
+		}
+
+		res = tagNot{res}
+	case "(":
+		p.pos++
+		res, err = p.parseOr()
+		if err != nil {
+			return
+		}
+
+		if p.peek() != ")" {
+			err = errors.Errorf("missing closing parenthesis")
+			return
+		}
+		p.pos++
+	case ")", "&&", "||":
+		err = errors.Errorf("unexpected '%s'", token)
+	default:
+		p.pos++
+		res = tagLiteral(token)
+	}
+
+	return
+}
+
+// SelectJobsByTags lists the ids of the jobs whose tags
+// match the `include` expression and don't match the
+// `exclude` one. Empty expressions are ignored.
+func SelectJobsByTags(jobs []*Job, include, exclude string) (ids []string, err error) {
+	var includeExpr, excludeExpr TagExpression
+
+	if include != "" {
+		includeExpr, err = ParseTagExpression(include)
+		if err != nil {
+			return
+		}
+	}
+
+	if exclude != "" {
+		excludeExpr, err = ParseTagExpression(exclude)
+		if err != nil {
+			return
+		}
+	}
+
+	for _, job := range jobs {
+		tags := map[string]bool{}
+		for _, tag := range job.Tags {
+			tags[tag] = true
+		}
+
+		if includeExpr != nil && !includeExpr.Match(tags) {
+			continue
+		}
+
+		if excludeExpr != nil && excludeExpr.Match(tags) {
+			continue
+		}
+
+		ids = append(ids, job.Id)
+	}
+
+	return
+}
@@ -0,0 +1,237 @@
+package lib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTagExpression(t *testing.T) {
+	var testCases = []struct {
+		desc        string
+		expr        string
+		tags        []string
+		expected    bool
+		shouldError bool
+	}{
+		{
+			desc:        "empty",
+			expr:        "",
+			shouldError: true,
+		},
+		{
+			desc:     "single tag matching",
+			expr:     "unit",
+			tags:     []string{"unit"},
+			expected: true,
+		},
+		{
+			desc:     "single tag not matching",
+			expr:     "unit",
+			tags:     []string{"lint"},
+			expected: false,
+		},
+		{
+			desc:     "negation",
+			expr:     "unit && !slow",
+			tags:     []string{"unit", "slow"},
+			expected: false,
+		},
+		{
+			desc:     "or",
+			expr:     "lint || unit",
+			tags:     []string{"unit"},
+			expected: true,
+		},
+		{
+			desc:     "and binds tighter than or",
+			expr:     "lint || unit && slow",
+			tags:     []string{"lint"},
+			expected: true,
+		},
+		{
+			desc:     "parenthesis",
+			expr:     "(lint || unit) && slow",
+			tags:     []string{"lint"},
+			expected: false,
+		},
+		{
+			desc:        "unbalanced parenthesis",
+			expr:        "(lint || unit",
+			shouldError: true,
+		},
+		{
+			desc:        "dangling operator",
+			expr:        "lint &&",
+			shouldError: true,
+		},
+		{
+			desc:        "missing operator",
+			expr:        "lint unit",
+			shouldError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			expr, err := ParseTagExpression(tc.expr)
+			if tc.shouldError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			tags := map[string]bool{}
+			for _, tag := range tc.tags {
+				tags[tag] = true
+			}
+
+			assert.Equal(t, tc.expected, expr.Match(tags))
+		})
+	}
+}
+
+func TestSelectJobsByTags(t *testing.T) {
+	var jobs = []*Job{
+		{Id: "setup"},
+		{Id: "lint", Tags: []string{"lint"}},
+		{Id: "unit", Tags: []string{"unit"}},
+		{Id: "integration", Tags: []string{"unit", "slow"}},
+	}
+
+	var testCases = []struct {
+		desc     string
+		include  string
+		exclude  string
+		expected []string
+	}{
+		{
+			desc:     "include",
+			include:  "unit",
+			expected: []string{"unit", "integration"},
+		},
+		{
+			desc:     "exclude",
+			exclude:  "slow",
+			expected: []string{"setup", "lint", "unit"},
+		},
+		{
+			desc:     "include and exclude",
+			include:  "unit || lint",
+			exclude:  "slow",
+			expected: []string{"lint", "unit"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			actual, err := SelectJobsByTags(jobs, tc.include, tc.exclude)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestNewReincludedJobs(t *testing.T) {
+	var testCases = []struct {
+		desc     string
+		runtime  Runtime
+		expected []string
+	}{
+		{
+			desc:     "no exclusion",
+			runtime:  Runtime{Tags: "unit"},
+			expected: nil,
+		},
+		{
+			desc:     "excluded job not needed",
+			runtime:  Runtime{Tags: "unit", ExcludeTags: "lint"},
+			expected: nil,
+		},
+		{
+			desc:     "excluded job pulled in as a dependency",
+			runtime:  Runtime{Tags: "unit", ExcludeTags: "slow"},
+			expected: []string{"build"},
+		},
+		{
+			desc:     "excluded job listed as a target",
+			runtime:  Runtime{ExcludeTags: "lint", Targets: []string{"lint"}},
+			expected: nil,
+		},
+		{
+			desc:     "excluded job needed by a target",
+			runtime:  Runtime{ExcludeTags: "slow", Targets: []string{"test"}},
+			expected: []string{"build"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tc.runtime.LogsDirectory = t.TempDir()
+
+			e, err := New(&Config{
+				Runtime: tc.runtime,
+				Jobs: []*Job{
+					{Id: "build", Run: "true", Tags: []string{"slow"}},
+					{Id: "lint", Run: "true", Tags: []string{"lint"}},
+					{Id: "test", Run: "true", Tags: []string{"unit"}, DependsOn: []string{"build"}},
+				},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, e.ReincludedJobs())
+		})
+	}
+}
+
+func TestNewExcludeTagsSelection(t *testing.T) {
+	var testCases = []struct {
+		desc     string
+		runtime  Runtime
+		expected []string
+	}{
+		{
+			desc:     "exclusion alone",
+			runtime:  Runtime{ExcludeTags: "slow"},
+			expected: []string{"lint"},
+		},
+		{
+			desc:     "exclusion with targets",
+			runtime:  Runtime{ExcludeTags: "slow", Targets: []string{"test"}},
+			expected: []string{"build", "test"},
+		},
+		{
+			desc:     "exclusion with an excluded target",
+			runtime:  Runtime{ExcludeTags: "slow", Targets: []string{"build"}},
+			expected: []string{"build"},
+		},
+		{
+			desc:     "exclusion with tags and targets",
+			runtime:  Runtime{Tags: "lint", ExcludeTags: "slow", Targets: []string{"build"}},
+			expected: []string{"build", "lint"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			tc.runtime.LogsDirectory = t.TempDir()
+
+			e, err := New(&Config{
+				Runtime: tc.runtime,
+				Jobs: []*Job{
+					{Id: "build", Run: "true", Tags: []string{"slow"}},
+					{Id: "lint", Run: "true", Tags: []string{"lint"}},
+					{Id: "test", Run: "true", Tags: []string{"slow"}, DependsOn: []string{"build"}},
+				},
+			})
+			require.NoError(t, err)
+
+			var actual []string
+			for _, job := range e.SortedJobs() {
+				actual = append(actual, job.Id)
+			}
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
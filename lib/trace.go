@@ -0,0 +1,173 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TraceEvent is an event of the Chrome Trace Event format,
+// as understood by `chrome://tracing` and Perfetto.
+type TraceEvent struct {
+	Name      string                 `json:"name"`
+	Category  string                 `json:"cat,omitempty"`
+	Phase     string                 `json:"ph"`
+	Timestamp int64                  `json:"ts"`
+	Duration  int64                  `json:"dur,omitempty"`
+	Pid       int                    `json:"pid"`
+	Tid       int                    `json:"tid"`
+	Id        int                    `json:"id,omitempty"`
+	BindPoint string                 `json:"bp,omitempty"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+}
+
+// Trace is a Chrome Trace Event document.
+type Trace struct {
+	TraceEvents     []*TraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string        `json:"displayTimeUnit"`
+}
+
+// traceSpan is a job that has been executed in this run,
+// placed on the lane it's displayed at.
+type traceSpan struct {
+	job  *Job
+	lane int
+}
+
+// GetTrace builds a timeline of the execution where each
+// job executed in this run is a complete event.
+// Jobs are spread over lanes so that the ones running at the
+// same time never share a lane, and each dependency is
+// represented by a flow arrow going from the end of the
+// dependency to the start of the dependent job.
+func (e *Executor) GetTrace() (trace *Trace) {
+	var (
+		spans    []*traceSpan
+		laneEnds []time.Time
+		byId     = map[string]*traceSpan{}
+	)
+
+	trace = &Trace{
+		TraceEvents:     []*TraceEvent{},
+		DisplayTimeUnit: "ms",
+	}
+
+	micros := func(t time.Time) int64 {
+		return t.Sub(e.startTime).Microseconds()
+	}
+
+	for _, job := range e.config.Jobs {
+		if !e.graph.HasVertex(job) || e.restored[job.Id] ||
+			job.StartTime == nil || job.EndTime == nil {
+			continue
+		}
+
+		spans = append(spans, &traceSpan{job: job})
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].job.StartTime.Before(*spans[j].job.StartTime)
+	})
+
+	for _, span := range spans {
+		span.lane = -1
+
+		for lane, end := range laneEnds {
+			if !end.After(*span.job.StartTime) {
+				span.lane = lane
+				break
+			}
+		}
+
+		if span.lane == -1 {
+			span.lane = len(laneEnds)
+			laneEnds = append(laneEnds, time.Time{})
+		}
+
+		laneEnds[span.lane] = *span.job.EndTime
+		byId[span.job.Id] = span
+	}
+
+	trace.TraceEvents = append(trace.TraceEvents, &TraceEvent{
+		Name:  "process_name",
+		Phase: "M",
+		Args:  map[string]interface{}{"name": "cr " + e.runId},
+	})
+
+	for lane := range laneEnds {
+		trace.TraceEvents = append(trace.TraceEvents, &TraceEvent{
+			Name:  "thread_name",
+			Phase: "M",
+			Tid:   lane,
+			Args: map[string]interface{}{
+				"name": fmt.Sprintf("lane %d", lane),
+			},
+		})
+	}
+
+	for _, span := range spans {
+		job := span.job
+
+		trace.TraceEvents = append(trace.TraceEvents, &TraceEvent{
+			Name:      job.Id,
+			Category:  "job",
+			Phase:     "X",
+			Timestamp: micros(*job.StartTime),
+			Duration:  job.EndTime.Sub(*job.StartTime).Microseconds(),
+			Tid:       span.lane,
+			Args: map[string]interface{}{
+				"status":   ActivityMapping[job.Status],
+				"exitCode": job.ExitCode,
+				"argv":     e.JobArgv(job),
+			},
+		})
+	}
+
+	flowId := 0
+	for _, span := range spans {
+		for _, dep := range span.job.DependsOn {
+			depSpan, present := byId[dep]
+			if !present {
+				continue
+			}
+
+			flowId++
+			trace.TraceEvents = append(trace.TraceEvents,
+				&TraceEvent{
+					Name:      "depends on",
+					Category:  "dependency",
+					Phase:     "s",
+					Timestamp: micros(*depSpan.job.EndTime),
+					Tid:       depSpan.lane,
+					Id:        flowId,
+				},
+				&TraceEvent{
+					Name:      "depends on",
+					Category:  "dependency",
+					Phase:     "f",
+					BindPoint: "e",
+					Timestamp: micros(*span.job.StartTime),
+					Tid:       span.lane,
+					Id:        flowId,
+				})
+		}
+	}
+
+	return
+}
+
+// WriteTrace writes the timeline of the execution in the
+// Chrome Trace Event format.
+func (e *Executor) WriteTrace(w io.Writer) (err error) {
+	err = json.NewEncoder(w).Encode(e.GetTrace())
+	if err != nil {
+		err = errors.Wrapf(err, "failed to write trace")
+		return
+	}
+
+	return
+}
@@ -0,0 +1,52 @@
+package lib
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTrace(t *testing.T) {
+	e, err := New(&Config{
+		Runtime: Runtime{LogsDirectory: t.TempDir()},
+		Jobs: []*Job{
+			{Id: "a", Run: "sleep 0.2"},
+			{Id: "b", Run: "sleep 0.2"},
+			{Id: "c", Run: "true", DependsOn: []string{"a", "b"}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, e.Execute(context.Background()))
+
+	var (
+		lanes = map[string]int{}
+		flows = map[string][]*TraceEvent{}
+	)
+
+	for _, event := range e.GetTrace().TraceEvents {
+		switch event.Phase {
+		case "X":
+			lanes[event.Name] = event.Tid
+			assert.True(t, event.Duration > 0)
+		case "s", "f":
+			flows[event.Phase] = append(flows[event.Phase], event)
+		}
+	}
+
+	require.Len(t, lanes, 3)
+	assert.NotEqual(t, lanes["a"], lanes["b"])
+	assert.Contains(t, []int{lanes["a"], lanes["b"]}, lanes["c"])
+
+	require.Len(t, flows["s"], 2)
+	require.Len(t, flows["f"], 2)
+
+	for idx := range flows["s"] {
+		start, finish := flows["s"][idx], flows["f"][idx]
+
+		assert.Equal(t, start.Id, finish.Id)
+		assert.Equal(t, lanes["c"], finish.Tid)
+		assert.True(t, start.Timestamp <= finish.Timestamp)
+	}
+}
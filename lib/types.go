@@ -2,8 +2,12 @@ package lib
 
 import (
 	"io"
-	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/hashicorp/terraform/dag"
 )
 
 // Execution represents the instantiation of a command
@@ -18,13 +22,19 @@ type Execution struct {
 	StartTime time.Time
 	EndTime   time.Time
 
-	cmd *exec.Cmd
+	// Runner carries out the execution. Defaults
+	// to running a local process.
+	Runner Runner
 }
 
 // RenderState encapsulates the state that can
 // be used when templating a given field.
 type RenderState struct {
 	Jobs map[string]*Job
+
+	// Matrix holds the values of the matrix of the
+	// job being rendered (if any).
+	Matrix map[string]string
 }
 
 // Config aggregates all the types of cofiguration
@@ -40,12 +50,27 @@ type Config struct {
 	// be applied to every execution
 	Env map[string]string `yaml:"Env"`
 
+	// Resources maps the name of resource pools to how
+	// many jobs can make use of each of them at once.
+	Resources map[string]int `yaml:"Resources"`
+
 	// Jobs lists the jobs to be executed.
 	Jobs []*Job `yaml:"Jobs"`
 
 	// OnJobStatusChange is a callback function to be called
 	// once per transition of job status.
 	OnJobStatusChange func(a *Activity) `yaml:"-"`
+
+	// OutputLock is held while writing the output of jobs to
+	// stdout and stderr. Sharing it with whatever
+	// OnJobStatusChange writes with (e.g. a Ui) keeps the
+	// output of both from interleaving.
+	OutputLock sync.Locker `yaml:"-"`
+
+	// Runners maps names to custom runners that jobs can
+	// select via `Job.Runner`, in addition to the `local`
+	// one.
+	Runners map[string]Runner `yaml:"-"`
 }
 
 // Runtime aggragates CLI and runtime configuration
@@ -61,8 +86,19 @@ type Runtime struct {
 
 	// Stdout indicates whether the execution logs should be pipped
 	// to stdout or not.
+	// Each line gets prefixed with the id of the job that
+	// wrote it.
 	Stdout bool `arg:"help:log executions to stdout" yaml:"Stdout"`
 
+	// Output indicates how the output of the jobs should be
+	// shown: `stream` (same as Stdout) or `grouped`, writing the
+	// output of each job as a single block once it finishes.
+	Output string `arg:"help:show the output of jobs as a stream or grouped per job" yaml:"Output"`
+
+	// Timestamps indicates whether the lines logged to stdout
+	// should also be prefixed with the time they were written.
+	Timestamps bool `arg:"help:prefix the lines logged to stdout with the time" yaml:"Timestamps"`
+
 	// Graph indicates whether a dot graph should be output
 	// or not.
 	Graph bool `arg:"help:output the execution graph" yaml:"Graph"`
@@ -71,6 +107,78 @@ type Runtime struct {
 	// for the executions when a relative path is indicated in the
 	// job description.
 	Directory string `arg:"help:directory to be used as current working directory" yaml:"Directory"`
+
+	// FailFast indicates whether the whole execution should be
+	// aborted as soon as a single job fails, killing the jobs
+	// that are still running.
+	FailFast bool `arg:"--fail-fast,help:abort all running jobs as soon as one fails" yaml:"FailFast"`
+
+	// KeepGoing indicates whether the jobs that didn't run
+	// because one of their dependencies failed should be
+	// reported as skipped, with the resulting error listing
+	// every job that failed. Jobs that don't depend on failed
+	// ones are always executed.
+	KeepGoing bool `arg:"--keep-going,help:report the dependents of failed jobs as skipped and list every failed job" yaml:"KeepGoing"`
+
+	// Timeout limits the duration of the whole execution. Jobs
+	// still running once it expires get killed.
+	Timeout time.Duration `arg:"help:maximum duration of the whole execution" yaml:"Timeout"`
+
+	// MaxParallel limits the number of jobs running at the
+	// same time. Zero means no limit.
+	MaxParallel int `arg:"-j,--jobs,help:maximum number of jobs to run in parallel" yaml:"MaxParallel"`
+
+	// Targets lists the ids of the jobs to run. When set, only
+	// these jobs and the ones they depend on are executed.
+	Targets []string `arg:"positional,help:ids of the jobs to run (along with their dependencies)" yaml:"Targets"`
+
+	// Skip lists the ids of the jobs that must not be run.
+	// Jobs depending on them are not run either.
+	Skip []string `arg:"separate,help:id of a job to exclude (along with its dependents)" yaml:"Skip"`
+
+	// Tags is an expression (e.g. `unit && !slow`) selecting
+	// the jobs to run based on their tags. The jobs they
+	// depend on are always run. The selected jobs are run
+	// in addition to the ones listed in Targets.
+	Tags string `arg:"help:expression selecting the jobs to run by their tags (in addition to TARGETS)" yaml:"Tags"`
+
+	// ExcludeTags is an expression that deselects the jobs
+	// whose tags match it. It only narrows down the selection
+	// by Tags or, without Tags and Targets, the whole plan:
+	// deselected jobs still run when listed in Targets or
+	// when a job that runs depends on them.
+	ExcludeTags string `arg:"--exclude-tags,help:expression deselecting jobs by their tags (targets and dependencies still run)" yaml:"ExcludeTags"`
+
+	// DryRun indicates whether the execution plan should be
+	// printed instead of running the jobs.
+	DryRun bool `arg:"--dry-run,help:print what would be executed without running anything" yaml:"DryRun"`
+
+	// Resume indicates whether the jobs that succeeded in the
+	// previous run of the same configuration file should be
+	// reused instead of executed again.
+	Resume bool `arg:"help:only run the jobs that failed or didn't run in the previous run of the same file" yaml:"Resume"`
+
+	// ReportJunit is the path to the file where a JUnit XML
+	// report of the execution should be written to.
+	ReportJunit string `arg:"--report-junit,help:path to write a JUnit XML report to" yaml:"ReportJunit"`
+
+	// ReportJson is the path to the file where a JSON summary
+	// of the execution should be written to (`-` for stdout).
+	ReportJson string `arg:"--report-json,help:path to write a JSON summary to (- for stdout)" yaml:"ReportJson"`
+
+	// Trace is the path to the file where a timeline of the
+	// execution in the Chrome Trace Event format should be
+	// written to.
+	Trace string `arg:"--trace,help:path to write a Chrome trace of the execution to" yaml:"Trace"`
+
+	// Shell is the default shell (and its arguments) that
+	// executes the `Run` command of the jobs.
+	// Defaults to `/bin/bash -c`.
+	Shell []string `arg:"-" yaml:"Shell,flow"`
+
+	// NoTty disables the live dashboard that's displayed
+	// when the output goes to a terminal.
+	NoTty bool `arg:"--no-tty,help:disable the live dashboard even on a terminal" yaml:"NoTty"`
 }
 
 // Job defines a unit of execution that at some point
@@ -83,9 +191,46 @@ type Job struct {
 	Id string `yaml:"Id"`
 
 	// Run is a command to execute in the context
-	// of a default shell.
+	// of a shell (see Shell).
 	Run string `yaml:"Run"`
 
+	// Shell is the shell (and its arguments) that
+	// executes `Run`, taking precedence over the one
+	// from the Runtime.
+	Shell []string `yaml:"Shell,flow"`
+
+	// Runner is the name of the runner that executes
+	// the command of the job. Defaults to `local`,
+	// i.e., running it as a local process.
+	Runner string `yaml:"Runner"`
+
+	// Matrix expands the job into one job per combination
+	// of values, with ids like `id[axis=value,...]`.
+	Matrix *Matrix `yaml:"Matrix"`
+
+	// MatrixValues holds the values of the combination
+	// that a job expanded from a Matrix stands for.
+	MatrixValues map[string]string `yaml:"-"`
+
+	// executed indicates whether the command of the job
+	// actually got executed in the current run, as opposed
+	// to jobs that succeed without running anything.
+	executed bool
+
+	// matrixOf is the id of the job with a Matrix that
+	// the job expanded from.
+	matrixOf string
+
+	// Func is a Go function that implements the job,
+	// being an alternative to Run and Command (which
+	// gets passed to it as arguments). See AddFuncJob.
+	Func JobFunc `yaml:"-"`
+
+	// Command is an alternative to Run that executes
+	// the given argv directly, without a shell. Each
+	// element is templated separately.
+	Command []string `yaml:"Command,flow"`
+
 	// Directory names the absolute or relative path
 	// to get into before executin the command.
 	// By default it takes the value "." (current working
@@ -116,6 +261,10 @@ type Job struct {
 	// has been executed.
 	Output string `yaml:"-"`
 
+	// Status holds the type of the last activity
+	// reported for the job.
+	Status ActivityType `yaml:"-"`
+
 	// DependsOn lists a series of jobs that the job depends
 	// on to start its execution.
 	DependsOn []string `yaml:"DependsOn,flow"`
@@ -123,8 +272,116 @@ type Job struct {
 	// LogFilepath indicates the path to the file where the logs
 	// of the job execution are sent to.
 	LogFilepath string `yaml:"LogFilepath"`
+
+	// Retries indicates how many times the command should be
+	// retried when it exits with a non-zero exit code.
+	Retries int `yaml:"Retries"`
+
+	// RetryDelay is the time to wait before the first retry.
+	RetryDelay time.Duration `yaml:"RetryDelay"`
+
+	// RetryBackoff is the factor by which RetryDelay gets
+	// multiplied after each retry. Defaults to 1 (constant
+	// delay).
+	RetryBackoff float64 `yaml:"RetryBackoff"`
+
+	// Timeout limits the duration of each attempt of
+	// executing the command.
+	Timeout time.Duration `yaml:"Timeout"`
+
+	// Uses lists the resource pools that the job needs
+	// to acquire before starting its execution.
+	Uses []string `yaml:"Uses,flow"`
+
+	// Tags labels the job so that it can be selected
+	// with the `Tags` and `ExcludeTags` runtime options.
+	Tags []string `yaml:"Tags,flow"`
+
+	// Inputs lists glob patterns of the files that the job
+	// consumes (`**` matches any number of directories).
+	// When set, the job is skipped if neither these files,
+	// the job definition nor its dependencies changed since
+	// its last successful execution.
+	Inputs []string `yaml:"Inputs,flow"`
+
+	// Outputs lists the paths that the job produces. A cached
+	// execution is only reused if all of them still exist.
+	Outputs []string `yaml:"Outputs,flow"`
+
+	// Creates lists the files that the job generates. When
+	// all of them are newer than every file matching
+	// `Sources` (and no dependency has been executed), the
+	// job is considered up to date and doesn't run.
+	Creates []string `yaml:"Creates,flow"`
+
+	// Sources lists glob patterns of the files that the
+	// job generates its `Creates` from (`**` matches any
+	// number of directories).
+	Sources []string `yaml:"Sources,flow"`
+
+	// CacheKey is the hash identifying the inputs of the
+	// last execution of the job.
+	CacheKey string `yaml:"-"`
 }
 
 func (j Job) Name() string {
 	return j.Id
 }
+
+// runnerName retrieves the name of the runner that
+// executes the job.
+func (j *Job) runnerName() string {
+	if j.Runner == "" {
+		return LocalRunnerName
+	}
+
+	return j.Runner
+}
+
+// HasCommand indicates whether the job executes anything,
+// either via Run, Command or Func.
+func (j *Job) HasCommand() bool {
+	return j.Run != "" || len(j.Command) > 0 || j.Func != nil
+}
+
+// DotNode implements dag.GraphNodeDotter, annotating
+// in the dot graph the resources used by the job.
+func (j *Job) DotNode(name string, opts *dag.DotOpts) (node *dag.DotNode) {
+	if len(j.Uses) == 0 {
+		return
+	}
+
+	node = &dag.DotNode{
+		Name: name,
+		Attrs: map[string]string{
+			"label": j.Id + "\n(uses: " + strings.Join(j.Uses, ", ") + ")",
+		},
+	}
+
+	return
+}
+
+// resolvePath makes a path relative to the directory
+// of the job unless it's already absolute.
+func (j Job) resolvePath(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+
+	return filepath.Join(j.Directory, p)
+}
+
+// RetryDelayFor computes how long to wait before retrying
+// the job after the given (1-indexed) failed attempt.
+func (j Job) RetryDelayFor(attempt int) (res time.Duration) {
+	res = j.RetryDelay
+	if j.RetryBackoff <= 0 {
+		return
+	}
+
+	for i := 1; i < attempt; i++ {
+		res = time.Duration(float64(res) * j.RetryBackoff)
+	}
+
+	return
+}
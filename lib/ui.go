@@ -1,7 +1,11 @@
 package lib
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"text/tabwriter"
 	"time"
@@ -18,28 +22,63 @@ const (
 	ActivityErrored
 	ActivitySuccess
 	ActivityAborted
+	ActivitySkipped
+	ActivityRetrying
+	ActivityTimeout
+	ActivityQueued
+	ActivityCached
+	ActivityUpToDate
+	ActivityRestored
 )
 
 type Activity struct {
 	Type ActivityType
 	Time time.Time
 	Job  *Job
+
+	// Attempt is the number of the attempt that
+	// just failed when retrying a job.
+	Attempt int
+
+	// Timeout is the limit that got exceeded when
+	// a job times out.
+	Timeout time.Duration
+
+	// Running and Queued hold the number of jobs
+	// running and waiting for a slot at the time
+	// of the activity.
+	Running int
+	Queued  int
 }
 
 var (
 	ActivityMapping = map[ActivityType]string{
-		ActivityAborted: "ABORTED",
-		ActivityStarted: "STARTED",
-		ActivityErrored: "ERRORED",
-		ActivitySuccess: "SUCCESS",
-		ActivityUnknown: "UNKNOWN",
+		ActivityAborted:  "ABORTED",
+		ActivitySkipped:  "SKIPPED",
+		ActivityRetrying: "RETRYING",
+		ActivityTimeout:  "TIMEOUT",
+		ActivityQueued:   "QUEUED",
+		ActivityCached:   "CACHED",
+		ActivityUpToDate: "UP-TO-DATE",
+		ActivityRestored: "RESTORED",
+		ActivityStarted:  "STARTED",
+		ActivityErrored:  "ERRORED",
+		ActivitySuccess:  "SUCCESS",
+		ActivityUnknown:  "UNKNOWN",
 	}
 	WriterMapping = map[ActivityType]*color.Color{
-		ActivityAborted: color.New(color.FgYellow),
-		ActivityStarted: color.New(color.FgBlue),
-		ActivityErrored: color.New(color.FgRed),
-		ActivitySuccess: color.New(color.FgGreen),
-		ActivityUnknown: color.New(color.FgCyan),
+		ActivityAborted:  color.New(color.FgYellow),
+		ActivitySkipped:  color.New(color.FgMagenta),
+		ActivityRetrying: color.New(color.FgYellow),
+		ActivityTimeout:  color.New(color.FgRed),
+		ActivityQueued:   color.New(color.FgCyan),
+		ActivityCached:   color.New(color.FgGreen),
+		ActivityUpToDate: color.New(color.FgGreen),
+		ActivityRestored: color.New(color.FgGreen),
+		ActivityStarted:  color.New(color.FgBlue),
+		ActivityErrored:  color.New(color.FgRed),
+		ActivitySuccess:  color.New(color.FgGreen),
+		ActivityUnknown:  color.New(color.FgCyan),
 	}
 )
 
@@ -49,12 +88,20 @@ type Ui struct {
 }
 
 func NewUi() (u Ui) {
-	u.writer = new(tabwriter.Writer)
-	u.writer.Init(os.Stdout, 10, 8, 2, '\t', 0)
+	u.SetWriter(os.Stdout)
 
 	return
 }
 
+// SetWriter changes where the Ui writes to.
+func (u *Ui) SetWriter(w io.Writer) {
+	u.Lock()
+	defer u.Unlock()
+
+	u.writer = new(tabwriter.Writer)
+	u.writer.Init(w, 10, 8, 2, '\t', 0)
+}
+
 func (u *Ui) WriteActivity(a *Activity) (err error) {
 	u.Lock()
 	defer u.Unlock()
@@ -62,11 +109,44 @@ func (u *Ui) WriteActivity(a *Activity) (err error) {
 	switch a.Type {
 	case ActivityStarted:
 		WriterMapping[a.Type].
-			Fprintf(u.writer, "%s\tstatus=%s\tstart=%s\n",
+			Fprintf(u.writer, "%s\tstatus=%s\tstart=%s\trunning=%d\tqueued=%d\n",
 				a.Job.Id,
 				ActivityMapping[a.Type],
-				time.Now().Format("15:04:05"))
-	case ActivityErrored, ActivitySuccess, ActivityAborted:
+				time.Now().Format("15:04:05"),
+				a.Running,
+				a.Queued)
+	case ActivityQueued:
+		WriterMapping[a.Type].
+			Fprintf(u.writer, "%s\tstatus=%s\trunning=%d\tqueued=%d\n",
+				a.Job.Id,
+				ActivityMapping[a.Type],
+				a.Running,
+				a.Queued)
+	case ActivityRetrying:
+		WriterMapping[a.Type].
+			Fprintf(u.writer, "%s\tstatus=%s\tattempt=%d/%d\texit=%d\n",
+				a.Job.Id,
+				ActivityMapping[a.Type],
+				a.Attempt,
+				a.Job.Retries+1,
+				a.Job.ExitCode)
+	case ActivityTimeout:
+		WriterMapping[a.Type].
+			Fprintf(u.writer, "%s\tstatus=%s\tstart=%s\telapsed=%s\tlimit=%s\n",
+				a.Job.Id,
+				ActivityMapping[a.Type],
+				a.Job.StartTime.Format("15:04:05"),
+				a.Job.EndTime.Sub(*a.Job.StartTime).String(),
+				a.Timeout.String())
+	case ActivityErrored, ActivitySuccess, ActivityAborted, ActivitySkipped, ActivityCached, ActivityUpToDate, ActivityRestored:
+		if a.Job.StartTime == nil {
+			WriterMapping[a.Type].
+				Fprintf(u.writer, "%s\tstatus=%s\n",
+					a.Job.Id,
+					ActivityMapping[a.Type])
+			break
+		}
+
 		WriterMapping[a.Type].
 			Fprintf(u.writer, "%s\tstatus=%s\tstart=%s\telapsed=%s\n",
 				a.Job.Id,
@@ -82,3 +162,166 @@ func (u *Ui) WriteActivity(a *Activity) (err error) {
 	u.writer.Flush()
 	return
 }
+
+// WritePlan writes a human readable description of how
+// each job would be executed, highlighting the fields
+// that depend on the output of jobs that haven't run.
+func (u *Ui) WritePlan(plan []*PlannedJob) {
+	u.Lock()
+	defer u.Unlock()
+
+	pending := color.New(color.FgYellow)
+
+	for idx, p := range plan {
+		fmt.Fprintf(u.writer, "[%d] %s\n", idx+1, p.Job.Id)
+
+		writeField := func(name, value string) {
+			if deps, ok := p.Pending[name]; ok {
+				pending.Fprintf(u.writer, "    %s\t%s\t(pending output of %s)\n",
+					name, value, strings.Join(deps, ", "))
+				return
+			}
+
+			fmt.Fprintf(u.writer, "    %s\t%s\n", name, value)
+		}
+
+		writeField("Directory", p.Directory)
+		writeField("LogFilepath", p.LogFilepath)
+
+		keys := make([]string, 0, len(p.Env))
+		for k := range p.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			writeField("Env."+k, p.Env[k])
+		}
+
+		quoted := make([]string, len(p.Command))
+		for idx, arg := range p.Command {
+			quoted[idx] = fmt.Sprintf("%q", arg)
+		}
+
+		switch {
+		case p.Job.Func != nil:
+			writeField("Func", strings.Join(append([]string{"<go function>"}, quoted...), " "))
+		case len(p.Command) > 0:
+			writeField("Command", strings.Join(quoted, " "))
+		default:
+			writeField("Run", p.Run)
+		}
+		fmt.Fprintln(u.writer)
+	}
+
+	u.writer.Flush()
+}
+
+// activityTypeFromName maps the name of an activity type
+// back to the type itself.
+func activityTypeFromName(name string) ActivityType {
+	for t, n := range ActivityMapping {
+		if n == name {
+			return t
+		}
+	}
+
+	return ActivityUnknown
+}
+
+// WriteRuns writes a summary of each of the runs.
+func (u *Ui) WriteRuns(runs []*RunState) {
+	u.Lock()
+	defer u.Unlock()
+
+	fmt.Fprintf(u.writer, "ID\tSTART\tDURATION\tSUCCEEDED\tFAILED\n")
+
+	for _, run := range runs {
+		var succeeded, failed int
+
+		for _, job := range run.Jobs {
+			switch {
+			case job.Succeeded():
+				succeeded++
+			case job.Status == ActivityMapping[ActivityErrored],
+				job.Status == ActivityMapping[ActivityTimeout]:
+				failed++
+			}
+		}
+
+		fmt.Fprintf(u.writer, "%s\t%s\t%s\t%d\t%d\n",
+			run.Id,
+			run.StartTime.Format("2006-01-02 15:04:05"),
+			run.EndTime.Sub(run.StartTime).Round(time.Millisecond),
+			succeeded,
+			failed)
+	}
+
+	u.writer.Flush()
+}
+
+// WriteRun writes the final state of each job of a run.
+func (u *Ui) WriteRun(run *RunState) {
+	u.Lock()
+	defer u.Unlock()
+
+	for _, job := range run.Jobs {
+		writer := WriterMapping[activityTypeFromName(job.Status)]
+
+		if job.StartTime == nil || job.EndTime == nil {
+			writer.Fprintf(u.writer, "%s\tstatus=%s\n",
+				job.Id,
+				job.Status)
+			continue
+		}
+
+		writer.Fprintf(u.writer, "%s\tstatus=%s\texit=%d\tstart=%s\telapsed=%s\tlogs=%s\n",
+			job.Id,
+			job.Status,
+			job.ExitCode,
+			job.StartTime.Format("15:04:05"),
+			job.EndTime.Sub(*job.StartTime).String(),
+			job.LogFilepath)
+	}
+
+	u.writer.Flush()
+}
+
+// WriteCriticalPath writes the jobs on the critical path
+// followed by the slack of every job, so that it's clear
+// which ones are worth speeding up.
+func (u *Ui) WriteCriticalPath(cp *CriticalPath) {
+	u.Lock()
+	defer u.Unlock()
+
+	if len(cp.Jobs) == 0 {
+		return
+	}
+
+	fmt.Fprintf(u.writer, "\nCritical path (%s): %s\n\n",
+		cp.Duration.Round(time.Millisecond),
+		strings.Join(cp.Jobs, " -> "))
+
+	fmt.Fprintf(u.writer, "JOB\tDURATION\tSLACK\n")
+
+	ids := make([]string, 0, len(cp.Slack))
+	for id := range cp.Slack {
+		ids = append(ids, id)
+	}
+
+	sort.SliceStable(ids, func(i, j int) bool {
+		if cp.Slack[ids[i]] != cp.Slack[ids[j]] {
+			return cp.Slack[ids[i]] < cp.Slack[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	for _, id := range ids {
+		fmt.Fprintf(u.writer, "%s\t%s\t%s\n",
+			id,
+			cp.Durations[id].Round(time.Millisecond),
+			cp.Slack[id].Round(time.Millisecond))
+	}
+
+	u.writer.Flush()
+}
@@ -3,14 +3,20 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"math/rand"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/alexflint/go-arg"
 	"github.com/cirocosta/cr/lib"
+	"github.com/mattn/go-isatty"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
 
@@ -37,7 +43,9 @@ var (
 		With().
 		Str("from", "main").
 		Logger()
-	ui = lib.NewUi()
+	ui        = lib.NewUi()
+	dashboard *lib.Dashboard
+	out       = io.Writer(os.Stdout)
 )
 
 func must(err error) {
@@ -51,6 +59,13 @@ func must(err error) {
 }
 
 func main() {
+	// a job called `runs` can still be targeted as long
+	// as it's not the first argument (e.g. `cr --file x runs`)
+	if len(os.Args) > 1 && os.Args[1] == "runs" {
+		runsMain(os.Args[2:])
+		return
+	}
+
 	arg.MustParse(args)
 
 	rand.Seed(time.Now().UnixNano())
@@ -59,7 +74,18 @@ func main() {
 	cfg, err := lib.ConfigFromFile(args.File)
 	must(err)
 
+	cfg.Runtime.File = args.File
+
+	// keep the status lines from interleaving with the
+	// output of the jobs
+	cfg.OutputLock = &ui
+
 	cfg.OnJobStatusChange = func(a *lib.Activity) {
+		if dashboard != nil {
+			dashboard.WriteActivity(a)
+			return
+		}
+
 		ui.WriteActivity(a)
 	}
 
@@ -71,20 +97,177 @@ func main() {
 		cfg.Runtime.Stdout = true
 	}
 
+	if args.Output != "" {
+		cfg.Runtime.Output = args.Output
+	}
+
+	if args.Timestamps {
+		cfg.Runtime.Timestamps = true
+	}
+
+	if args.FailFast {
+		cfg.Runtime.FailFast = true
+	}
+
+	if args.KeepGoing {
+		cfg.Runtime.KeepGoing = true
+	}
+
+	if args.Timeout != 0 {
+		cfg.Runtime.Timeout = args.Timeout
+	}
+
+	if args.MaxParallel != 0 {
+		cfg.Runtime.MaxParallel = args.MaxParallel
+	}
+
+	if len(args.Targets) > 0 {
+		cfg.Runtime.Targets = args.Targets
+	}
+
+	if len(args.Skip) > 0 {
+		cfg.Runtime.Skip = args.Skip
+	}
+
+	if args.Tags != "" {
+		cfg.Runtime.Tags = args.Tags
+	}
+
+	if args.ExcludeTags != "" {
+		cfg.Runtime.ExcludeTags = args.ExcludeTags
+	}
+
+	if args.Resume {
+		cfg.Runtime.Resume = true
+	}
+
+	if args.ReportJunit != "" {
+		cfg.Runtime.ReportJunit = args.ReportJunit
+	}
+
+	if args.ReportJson != "" {
+		cfg.Runtime.ReportJson = args.ReportJson
+	}
+
+	if args.Trace != "" {
+		cfg.Runtime.Trace = args.Trace
+	}
+
+	if args.NoTty {
+		cfg.Runtime.NoTty = true
+	}
+
+	// keep stdout clean for the report when it goes there
+	if cfg.Runtime.ReportJson == "-" {
+		out = os.Stderr
+		ui.SetWriter(out)
+		logger = logger.Output(out)
+	}
+
 	executor, err := lib.New(&cfg)
 	must(err)
 
+	excluded := executor.ExcludedJobs()
+	if len(excluded) > 0 {
+		fmt.Fprintf(os.Stderr,
+			"Excluding jobs that depend on skipped ones: %s\n",
+			strings.Join(excluded, ", "))
+	}
+
+	reincluded := executor.ReincludedJobs()
+	if len(reincluded) > 0 {
+		fmt.Fprintf(os.Stderr,
+			"Warning: running jobs deselected by --exclude-tags as other jobs depend on them: %s\n",
+			strings.Join(reincluded, ", "))
+	}
+
 	if args.Graph {
 		fmt.Println(executor.GetDotGraph())
 		os.Exit(0)
 	}
 
-	fmt.Printf(`
+	if args.DryRun || cfg.Runtime.DryRun {
+		plan, err := executor.Plan()
+		must(err)
+
+		ui.WritePlan(plan)
+		os.Exit(0)
+	}
+
+	fmt.Fprintf(out, `
 	Starting execution.
 
+	Run id:		%s
 	Logs directory:	%s
-	`+"\n", cfg.Runtime.LogsDirectory)
+	`+"\n", executor.RunId(), executor.RunDirectory())
+
+	// the dashboard would get mixed up with the output
+	// of the jobs when it goes to stdout as well
+	if !cfg.Runtime.NoTty && !cfg.Runtime.Stdout && cfg.Runtime.Output == "" && isTerminal(out) {
+		dashboard = lib.NewDashboard(out, executor.SortedJobs())
+		dashboard.Start()
+	}
+
+	// cancelling the context kills the process groups of the
+	// running jobs so that none outlives cr. A second signal
+	// gets the default behavior.
+	ctx, stop := signal.NotifyContext(context.Background(),
+		os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err = executor.Execute(ctx)
+	stop()
+
+	if dashboard != nil {
+		dashboard.Stop()
+	}
+
+	ui.WriteCriticalPath(executor.GetCriticalPath())
+
+	if cfg.Runtime.ReportJunit != "" {
+		must(writeReport(cfg.Runtime.ReportJunit, executor.WriteJUnitReport))
+	}
+
+	if cfg.Runtime.ReportJson != "" {
+		must(writeReport(cfg.Runtime.ReportJson, executor.WriteJSONReport))
+	}
+
+	if cfg.Runtime.Trace != "" {
+		must(writeReport(cfg.Runtime.Trace, executor.WriteTrace))
+	}
 
-	err = executor.Execute(context.Background())
 	must(err)
 }
+
+// isTerminal indicates whether `w` is a terminal.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	return isatty.IsTerminal(file.Fd())
+}
+
+// writeReport writes a report to the file at `path`, or
+// to stdout if `path` is `-`.
+func writeReport(path string, write func(w io.Writer) error) (err error) {
+	if path == "-" {
+		err = write(os.Stdout)
+		return
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to create report file %s", path)
+		return
+	}
+	defer file.Close()
+
+	err = write(file)
+	return
+}
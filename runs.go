@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alexflint/go-arg"
+	"github.com/cirocosta/cr/lib"
+	"github.com/pkg/errors"
+)
+
+type runsArgs struct {
+	File          string   `arg:"help:path to the configuration file whose LogsDirectory to inspect"`
+	LogsDirectory string   `arg:"--logs-directory,help:path to the directory where logs are sent to (overrides the one of the configuration file)"`
+	Command       string   `arg:"positional,required,help:list or show <run-id> or logs <run-id> <job-id>"`
+	Args          []string `arg:"positional,help:arguments of the command"`
+}
+
+// runsMain implements the `cr runs` subcommand that
+// inspects the runs recorded in the logs directory.
+func runsMain(argv []string) {
+	var (
+		ra            = &runsArgs{}
+		run           *lib.RunState
+		logsDirectory string
+		err           error
+	)
+
+	p, err := arg.NewParser(arg.Config{Program: "cr runs"}, ra)
+	must(err)
+
+	err = p.Parse(argv)
+	if err == arg.ErrHelp {
+		p.WriteHelp(os.Stdout)
+		os.Exit(0)
+	}
+	if err != nil {
+		p.Fail(err.Error())
+	}
+
+	logsDirectory, err = runsLogsDirectory(ra)
+	must(err)
+
+	switch ra.Command {
+	case "list":
+		var (
+			runs    []*lib.RunState
+			invalid []error
+		)
+
+		runs, invalid, err = lib.ListRuns(logsDirectory)
+		must(err)
+
+		ui.WriteRuns(runs)
+
+		for _, err = range invalid {
+			fmt.Fprintf(os.Stderr, "Warning: skipping run: %s\n", err)
+		}
+	case "show":
+		if len(ra.Args) != 1 {
+			p.Fail("show requires a run id")
+		}
+
+		run, err = lib.LoadRun(logsDirectory, ra.Args[0])
+		must(err)
+
+		ui.WriteRun(run)
+	case "logs":
+		if len(ra.Args) != 2 {
+			p.Fail("logs requires a run id and a job id")
+		}
+
+		run, err = lib.LoadRun(logsDirectory, ra.Args[0])
+		must(err)
+
+		must(writeJobLogs(run, ra.Args[1]))
+	default:
+		p.Fail("unknown command " + ra.Command)
+	}
+}
+
+// runsLogsDirectory resolves the directory where the runs
+// are recorded: the one given with --logs-directory, the one
+// of the configuration file or the default one, in that order.
+// Not finding the configuration file is only an error when
+// it's given with --file.
+func runsLogsDirectory(ra *runsArgs) (dir string, err error) {
+	var cfg lib.Config
+
+	if ra.LogsDirectory != "" {
+		dir = ra.LogsDirectory
+		return
+	}
+
+	file := ra.File
+	if file == "" {
+		if _, statErr := os.Stat(args.File); statErr != nil {
+			dir = args.LogsDirectory
+			return
+		}
+
+		file = args.File
+	}
+
+	cfg, err = lib.ConfigFromFile(file)
+	if err != nil {
+		return
+	}
+
+	dir = cfg.Runtime.LogsDirectory
+	if dir == "" {
+		dir = args.LogsDirectory
+	}
+
+	return
+}
+
+func writeJobLogs(run *lib.RunState, id string) (err error) {
+	job, err := run.Job(id)
+	if err != nil {
+		return
+	}
+
+	if job.LogFilepath == "" {
+		err = errors.Errorf("job %s has no logs", id)
+		return
+	}
+
+	file, err := os.Open(job.LogFilepath)
+	if err != nil {
+		err = errors.Wrapf(err,
+			"failed to open logs of job %s", id)
+		return
+	}
+	defer file.Close()
+
+	_, err = io.Copy(os.Stdout, file)
+	return
+}